@@ -0,0 +1,39 @@
+package cfn
+
+import (
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// stackCache holds the DescribeStacks response of each stack described during the invocation, keyed by the name or ID it
+// was described by, until a call that changes a stack invalidates it
+var stackCache = struct {
+	sync.Mutex
+	stacks map[string]*cloudformation.DescribeStacksResponse
+}{stacks: make(map[string]*cloudformation.DescribeStacksResponse)}
+
+func cachedStack(stackName string) (*cloudformation.DescribeStacksResponse, bool) {
+	stackCache.Lock()
+	defer stackCache.Unlock()
+
+	stack, ok := stackCache.stacks[stackName]
+
+	return stack, ok
+}
+
+func cacheStack(stackName string, stack *cloudformation.DescribeStacksResponse) {
+	stackCache.Lock()
+	defer stackCache.Unlock()
+
+	stackCache.stacks[stackName] = stack
+}
+
+// InvalidateStackCache forgets every described stack, so the next GetStack sees the changes made since. Every call in this
+// package that changes a stack invalidates the cache itself
+func InvalidateStackCache() {
+	stackCache.Lock()
+	defer stackCache.Unlock()
+
+	stackCache.stacks = make(map[string]*cloudformation.DescribeStacksResponse)
+}
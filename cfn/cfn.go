@@ -1,12 +1,10 @@
 package cfn
 
 import (
-	"context"
-	"errors"
 	"fmt"
 	"strings"
+	"time"
 
-	"github.com/aws/aws-sdk-go-v2/aws/external"
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/blueseph/cirrus/colors"
 	"github.com/blueseph/cirrus/data"
@@ -20,15 +18,108 @@ var (
 		cloudformation.ChangeActionAdd:    "+",
 		cloudformation.ChangeActionRemove: "-",
 		cloudformation.ChangeActionModify: "↻ ",
+		cloudformation.ChangeActionImport: "←",
 	}
 )
 
+// ChangeSetOptions holds the optional settings used when creating a change set
+type ChangeSetOptions struct {
+	// ImportExisting imports resources that already exist instead of failing to create them
+	ImportExisting bool
+
+	// Description is shown with the change set in the console. Empty leaves it without one
+	Description string
+
+	// TemplateURL is the https URL of a template in S3, used instead of the template body for templates too large to send inline
+	TemplateURL string
+
+	// Capabilities are granted to the change set. Empty grants DefaultCapabilities
+	Capabilities []cloudformation.Capability
+
+	// ReplaceTags sends the tags even when there are none, removing every tag of an updated stack
+	ReplaceTags bool
+}
+
+// DefaultCapabilities are granted to change sets that don't name their capabilities, so any template can be deployed
+var DefaultCapabilities = []cloudformation.Capability{
+	cloudformation.CapabilityCapabilityAutoExpand,
+	cloudformation.CapabilityCapabilityIam,
+	cloudformation.CapabilityCapabilityNamedIam,
+}
+
+// ExecuteChangeSetOptions holds the optional settings used when executing a change set
+type ExecuteChangeSetOptions struct {
+	// DisableRollback leaves a failed stack in CREATE_FAILED or UPDATE_FAILED, with the failed resources in place to investigate
+	DisableRollback bool
+}
+
+// DeleteStackOptions holds the optional settings used when deleting a stack
+type DeleteStackOptions struct {
+	// ForceDelete deletes a stack stuck in DELETE_FAILED, abandoning the resources that failed to delete
+	ForceDelete bool
+
+	// RetainResources are the logical IDs of resources to leave in place when deleting a stack in DELETE_FAILED
+	RetainResources []string
+}
+
+// ChangeSetFailedError is returned when CloudFormation fails to create a change set, including when there is nothing to change
+type ChangeSetFailedError struct {
+	ChangeSetName string
+	Reason        string
+}
+
+func (e *ChangeSetFailedError) Error() string {
+	return colors.Error(fmt.Sprintf("Change set %s failed: %s", e.ChangeSetName, e.Reason))
+}
+
+// NoChanges reports whether the change set failed only because the template and parameters match the deployed stack
+func (e *ChangeSetFailedError) NoChanges() bool {
+	return data.IsNoChangesReason(e.Reason)
+}
+
+// ChangeSetNotExecutableError is returned instead of executing a change set CloudFormation would refuse, e.g. one that failed or is obsolete
+type ChangeSetNotExecutableError struct {
+	ChangeSetName   string
+	ExecutionStatus cloudformation.ExecutionStatus
+	Reason          string
+}
+
+func (e *ChangeSetNotExecutableError) Error() string {
+	message := fmt.Sprintf("Change set %s cannot be executed, its execution status is %s", e.ChangeSetName, e.ExecutionStatus)
+	if e.Reason != "" {
+		message += ": " + e.Reason
+	}
+
+	return colors.Error(message)
+}
+
+// VerifyExecutable returns a ChangeSetNotExecutableError unless the described change set is available to execute
+func VerifyExecutable(changeSet *cloudformation.DescribeChangeSetOutput) error {
+	if changeSet.ExecutionStatus == cloudformation.ExecutionStatusAvailable {
+		return nil
+	}
+
+	failure := &ChangeSetNotExecutableError{
+		ChangeSetName:   *changeSet.ChangeSetName,
+		ExecutionStatus: changeSet.ExecutionStatus,
+	}
+	if changeSet.StatusReason != nil {
+		failure.Reason = *changeSet.StatusReason
+	}
+
+	return failure
+}
+
 //StackOperation is the cloudFormation type of stack operations
 type StackOperation string
 
 const (
+	// MaxChangeSetDescriptionLength is the longest change set description CloudFormation accepts
+	MaxChangeSetDescriptionLength int = 1024
+
 	stackNotFound   string = "does not exist"
 	unknownEndpoint string = "unknown endpoint, could not resolve endpoint"
+	accessDenied    string = "AccessDenied"
 
 	//StackOperationUpdate is the enum value for Stack Operation of update
 	StackOperationUpdate StackOperation = "update"
@@ -42,20 +133,21 @@ const (
 
 func getClient() *cloudformation.Client {
 	if cfnClient == nil {
-		cfg, err := external.LoadDefaultAWSConfig()
+		cfg, err := loadConfig()
 		if err != nil {
 			panic(colors.Error(fmt.Sprintf("unable to load SDK config, %s", err.Error())))
 		}
 
 		cfnClient = cloudformation.New(cfg)
+		cfnClient.Handlers.Send.PushFront(withRateLimit(apiRateLimiter))
 	}
 
 	return cfnClient
 }
 
 //CreateChanges creates a change set, waits for it to complete creating, then describes the change set.
-func CreateChanges(info data.StackInfo, template []byte, tags []cloudformation.Tag, parameters []cloudformation.Parameter, exists bool) (*cloudformation.DescribeChangeSetResponse, error) {
-	err := createChangeSet(info, template, tags, parameters, exists)
+func CreateChanges(info data.StackInfo, template []byte, tags []cloudformation.Tag, parameters []cloudformation.Parameter, exists bool, options ChangeSetOptions) (*cloudformation.DescribeChangeSetResponse, error) {
+	err := createChangeSet(info, template, tags, parameters, exists, options)
 	if err != nil {
 		return nil, err
 	}
@@ -70,12 +162,11 @@ func CreateChanges(info data.StackInfo, template []byte, tags []cloudformation.T
 	return changes, err
 }
 
-func createChangeSet(info data.StackInfo, template []byte, tags []cloudformation.Tag, parameters []cloudformation.Parameter, exists bool) error {
+func createChangeSet(info data.StackInfo, template []byte, tags []cloudformation.Tag, parameters []cloudformation.Parameter, exists bool, options ChangeSetOptions) error {
 	stringTemplate := string(template)
-	capabilities := []cloudformation.Capability{
-		cloudformation.CapabilityCapabilityAutoExpand,
-		cloudformation.CapabilityCapabilityIam,
-		cloudformation.CapabilityCapabilityNamedIam,
+	capabilities := DefaultCapabilities
+	if len(options.Capabilities) > 0 {
+		capabilities = options.Capabilities
 	}
 
 	changeSetType := cloudformation.ChangeSetTypeCreate
@@ -88,16 +179,37 @@ func createChangeSet(info data.StackInfo, template []byte, tags []cloudformation
 	input := cloudformation.CreateChangeSetInput{
 		ChangeSetName: &info.ChangeSetName,
 		StackName:     &info.StackName,
-		TemplateBody:  &stringTemplate,
 		ChangeSetType: changeSetType,
 		Capabilities:  capabilities,
 		Parameters:    parameters,
-		Tags:          tags,
+	}
+
+	// an empty tag list is sent as an empty value, which removes every tag from the stack, while leaving tags out keeps the
+	// stack's current tags. Updating without tags must not wipe them unless asked to
+	if len(tags) > 0 || options.ReplaceTags {
+		input.Tags = append([]cloudformation.Tag{}, tags...)
+	}
+
+	if options.Description != "" {
+		input.Description = &options.Description
+	}
+
+	if options.TemplateURL != "" {
+		input.TemplateURL = &options.TemplateURL
+	} else {
+		input.TemplateBody = &stringTemplate
 	}
 
 	req := client.CreateChangeSetRequest(&input)
 
-	_, err := req.Send(context.Background())
+	// creating a change set for a new stack creates the stack
+	defer InvalidateStackCache()
+
+	if options.ImportExisting {
+		req.Handlers.Build.PushBack(withQueryParameter("ImportExistingResources", "true"))
+	}
+
+	_, err := req.Send(operationContext)
 	if err != nil {
 		return err
 	}
@@ -113,7 +225,7 @@ func waitForChangeSet(info data.StackInfo) error {
 		ChangeSetName: &info.ChangeSetName,
 	}
 
-	err := client.WaitUntilChangeSetCreateComplete(context.Background(), &input)
+	err := client.WaitUntilChangeSetCreateComplete(operationContext, &input)
 
 	if err != nil {
 		changeSet, innerErr := describeChangeSet(info)
@@ -122,7 +234,12 @@ func waitForChangeSet(info data.StackInfo) error {
 		}
 
 		if changeSet.Status == cloudformation.ChangeSetStatusFailed {
-			return errors.New(*changeSet.StatusReason)
+			failure := &ChangeSetFailedError{ChangeSetName: info.ChangeSetName}
+			if changeSet.StatusReason != nil {
+				failure.Reason = *changeSet.StatusReason
+			}
+
+			return failure
 		}
 		return err
 	}
@@ -130,8 +247,18 @@ func waitForChangeSet(info data.StackInfo) error {
 	return nil
 }
 
-// ExecuteChangeSet executes the given change set
-func ExecuteChangeSet(info data.StackInfo) error {
+// ExecuteChangeSet executes the given change set, unless its status shows CloudFormation would refuse to
+func ExecuteChangeSet(info data.StackInfo, options ExecuteChangeSetOptions) error {
+	changeSet, err := describeChangeSet(info)
+	if err != nil {
+		return err
+	}
+
+	err = VerifyExecutable(changeSet.DescribeChangeSetOutput)
+	if err != nil {
+		return err
+	}
+
 	input := cloudformation.ExecuteChangeSetInput{
 		StackName:     &info.StackName,
 		ChangeSetName: &info.ChangeSetName,
@@ -141,7 +268,31 @@ func ExecuteChangeSet(info data.StackInfo) error {
 
 	req := client.ExecuteChangeSetRequest(&input)
 
-	_, err := req.Send(context.Background())
+	if options.DisableRollback {
+		req.Handlers.Build.PushBack(withQueryParameter("DisableRollback", "true"))
+	}
+
+	defer InvalidateStackCache()
+
+	_, err = req.Send(operationContext)
+
+	return err
+}
+
+// DeleteChangeSet deletes the given change set, for change sets that will never be executed
+func DeleteChangeSet(info data.StackInfo) error {
+	input := cloudformation.DeleteChangeSetInput{
+		StackName:     &info.StackName,
+		ChangeSetName: &info.ChangeSetName,
+	}
+
+	client := getClient()
+
+	req := client.DeleteChangeSetRequest(&input)
+
+	defer InvalidateStackCache()
+
+	_, err := req.Send(operationContext)
 
 	return err
 }
@@ -156,7 +307,20 @@ func describeChangeSet(info data.StackInfo) (*cloudformation.DescribeChangeSetRe
 
 	req := client.DescribeChangeSetRequest(&input)
 
-	return req.Send(context.Background())
+	return req.Send(operationContext)
+}
+
+// DescribeChangeSetByID describes a change set from its ID (ARN) alone, for change sets cirrus did not create
+func DescribeChangeSetByID(changeSetID string) (*cloudformation.DescribeChangeSetResponse, error) {
+	input := cloudformation.DescribeChangeSetInput{
+		ChangeSetName: &changeSetID,
+	}
+
+	client := getClient()
+
+	req := client.DescribeChangeSetRequest(&input)
+
+	return req.Send(operationContext)
 }
 
 func getChanges(info data.StackInfo) ([]cloudformation.Change, error) {
@@ -165,8 +329,43 @@ func getChanges(info data.StackInfo) ([]cloudformation.Change, error) {
 	return changeSet.Changes, err
 }
 
-//GetStack retrieves the information for the given stack name
+// ValidateTemplate asks CloudFormation to validate a template body, or the template in S3 at templateURL when it's set, as it
+// would before creating a change set. The response declares the template's parameters and required capabilities
+func ValidateTemplate(body string, templateURL string) (*cloudformation.ValidateTemplateResponse, error) {
+	input := cloudformation.ValidateTemplateInput{}
+
+	if templateURL != "" {
+		input.TemplateURL = &templateURL
+	} else {
+		input.TemplateBody = &body
+	}
+
+	client := getClient()
+
+	req := client.ValidateTemplateRequest(&input)
+
+	return req.Send(operationContext)
+}
+
+//GetStack retrieves the information for the given stack name. A stack already described during this invocation is returned from
+//the cache, unless a change to a stack invalidated it since
 func GetStack(stackName string) (*cloudformation.DescribeStacksResponse, error) {
+	if stack, ok := cachedStack(stackName); ok {
+		return stack, nil
+	}
+
+	stack, err := RefreshStack(stackName)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheStack(stackName, stack)
+
+	return stack, nil
+}
+
+// RefreshStack describes the stack without the cache, for callers following a stack as it changes
+func RefreshStack(stackName string) (*cloudformation.DescribeStacksResponse, error) {
 	input := cloudformation.DescribeStacksInput{
 		StackName: &stackName,
 	}
@@ -175,7 +374,7 @@ func GetStack(stackName string) (*cloudformation.DescribeStacksResponse, error)
 
 	req := client.DescribeStacksRequest(&input)
 
-	stack, err := req.Send(context.Background())
+	stack, err := req.Send(operationContext)
 	if err != nil {
 		return nil, err
 	}
@@ -183,6 +382,75 @@ func GetStack(stackName string) (*cloudformation.DescribeStacksResponse, error)
 	return stack, err
 }
 
+//GetTemplate retrieves the template body of a deployed stack as it was originally submitted
+func GetTemplate(info data.StackInfo) (string, error) {
+	return getTemplateBody(info, cloudformation.TemplateStageOriginal)
+}
+
+// GetDeployedTemplate parses the template of a deployed stack as processed, after transforms such as SAM expand it, with short
+// form intrinsic functions expanded
+func GetDeployedTemplate(info data.StackInfo) (data.Template, error) {
+	body, err := getTemplateBody(info, cloudformation.TemplateStageProcessed)
+	if err != nil {
+		return data.Template{}, err
+	}
+
+	normalized, err := NormalizeTemplate(body)
+	if err != nil {
+		return data.Template{}, err
+	}
+
+	return data.ParseTemplate([]byte(normalized))
+}
+
+// GetDeletionPolicies returns the DeletionPolicy of every resource of a deployed stack, Delete when it declares none
+func GetDeletionPolicies(stackName string) (map[string]string, error) {
+	template, err := GetDeployedTemplate(data.StackInfo{StackName: stackName})
+	if err != nil {
+		return nil, err
+	}
+
+	return data.DeletionPolicies(template), nil
+}
+
+func getTemplateBody(info data.StackInfo, stage cloudformation.TemplateStage) (string, error) {
+	stack := stackIdentifier(info)
+
+	input := cloudformation.GetTemplateInput{
+		StackName:     &stack,
+		TemplateStage: stage,
+	}
+
+	client := getClient()
+
+	req := client.GetTemplateRequest(&input)
+
+	template, err := req.Send(operationContext)
+	if err != nil {
+		return "", err
+	}
+
+	return *template.TemplateBody, nil
+}
+
+// SetStackPolicy replaces the stack policy of a stack, which guards its resources against updates
+func SetStackPolicy(info data.StackInfo, policy string) error {
+	stack := stackIdentifier(info)
+
+	input := cloudformation.SetStackPolicyInput{
+		StackName:       &stack,
+		StackPolicyBody: &policy,
+	}
+
+	client := getClient()
+
+	req := client.SetStackPolicyRequest(&input)
+
+	_, err := req.Send(operationContext)
+
+	return err
+}
+
 // DetermineIfStackExists pulls a stack via the stackName and determines if it exists. If it is in a "review in progress" state, it counts as not existing
 func DetermineIfStackExists(stackName string) (bool, error) {
 	stack, err := GetStack(stackName)
@@ -199,7 +467,9 @@ func DetermineIfStackExists(stackName string) (bool, error) {
 
 	exists := true
 
-	if stack.Stacks[0].StackStatus == cloudformation.StackStatusReviewInProgress {
+	// stacks looked up by ID are still returned once deleted
+	switch stack.Stacks[0].StackStatus {
+	case cloudformation.StackStatusReviewInProgress, cloudformation.StackStatusDeleteComplete:
 		exists = false
 	}
 
@@ -212,7 +482,7 @@ func DetermineIfStackIsEmpty(info data.StackInfo) bool {
 
 	paginator := GetStackResources(info)
 
-	for paginator.Next(context.TODO()) {
+	for paginator.Next(operationContext) {
 		page := paginator.CurrentPage()
 
 		for _, resource := range page.StackResourceSummaries {
@@ -225,17 +495,26 @@ func DetermineIfStackIsEmpty(info data.StackInfo) bool {
 	return empty
 }
 
-// DeleteStack deletes the stack given a stack name
-func DeleteStack(info data.StackInfo) error {
+// DeleteStack deletes the stack given a stack name. If the stack ID is known, it is used instead so the exact stack generation is deleted
+func DeleteStack(info data.StackInfo, options DeleteStackOptions) error {
+	stack := stackIdentifier(info)
+
 	input := cloudformation.DeleteStackInput{
-		StackName: &info.StackName,
+		StackName:       &stack,
+		RetainResources: options.RetainResources,
 	}
 
 	client := getClient()
 
 	req := client.DeleteStackRequest(&input)
 
-	_, err := req.Send(context.Background())
+	defer InvalidateStackCache()
+
+	if options.ForceDelete {
+		req.Handlers.Build.PushBack(withQueryParameter("DeletionMode", "FORCE_DELETE_STACK"))
+	}
+
+	_, err := req.Send(operationContext)
 	if err != nil {
 		return err
 	}
@@ -245,7 +524,7 @@ func DeleteStack(info data.StackInfo) error {
 
 // DeleteStackAndWait deletes the stack and waits for a delete complete signal
 func DeleteStackAndWait(info data.StackInfo) error {
-	err := DeleteStack(info)
+	err := DeleteStack(info, DeleteStackOptions{})
 	if err != nil {
 		return err
 	}
@@ -260,12 +539,13 @@ func DeleteStackAndWait(info data.StackInfo) error {
 
 func waitForDeleteStack(info data.StackInfo) error {
 	client := getClient()
+	stack := stackIdentifier(info)
 
 	input := cloudformation.DescribeStacksInput{
-		StackName: &info.StackName,
+		StackName: &stack,
 	}
 
-	err := client.WaitUntilStackDeleteComplete(context.Background(), &input)
+	err := client.WaitUntilStackDeleteComplete(operationContext, &input)
 
 	if err != nil {
 		return err
@@ -274,25 +554,92 @@ func waitForDeleteStack(info data.StackInfo) error {
 	return nil
 }
 
-// GetStackEvents gets all the events from a particular CloudFormation stack
-func GetStackEvents(info data.StackInfo) cloudformation.DescribeStackEventsPaginator {
+func stackIdentifier(info data.StackInfo) string {
+	if info.StackID != "" {
+		return info.StackID
+	}
+
+	return info.StackName
+}
+
+// GetStackEvents gets at most limit events from a particular CloudFormation stack that occurred after the cutoff, newest first. A limit of zero or less fetches every event
+func GetStackEvents(info data.StackInfo, cutoff time.Time, limit int) ([]cloudformation.StackEvent, error) {
+	events := make([]cloudformation.StackEvent, 0)
+
+	paginator := getStackEventsPaginator(info)
+
+	for paginator.Next(operationContext) {
+		for _, event := range paginator.CurrentPage().StackEvents {
+			if !event.Timestamp.After(cutoff) || (limit > 0 && len(events) >= limit) {
+				return events, nil
+			}
+
+			events = append(events, event)
+		}
+	}
+
+	return events, paginator.Err()
+}
+
+// GetOperationEvents gets the events of the most recent stack operation, newest first, paging back until the event that started it.
+// At most limit events are fetched, so a long operation may be cut short. A limit of zero or less fetches the whole operation
+func GetOperationEvents(info data.StackInfo, limit int) ([]cloudformation.StackEvent, error) {
+	events := make([]cloudformation.StackEvent, 0)
+
+	paginator := getStackEventsPaginator(info)
+
+	for paginator.Next(operationContext) {
+		for _, event := range paginator.CurrentPage().StackEvents {
+			if limit > 0 && len(events) >= limit {
+				return events, nil
+			}
+
+			events = append(events, event)
+
+			if data.IsOperationStart(event) {
+				return events, nil
+			}
+		}
+	}
+
+	return events, paginator.Err()
+}
+
+// GetLatestStackEventTime gets the timestamp of the most recent event of a particular CloudFormation stack, or the zero time if it has none
+func GetLatestStackEventTime(info data.StackInfo) (time.Time, error) {
+	paginator := getStackEventsPaginator(info)
+
+	if paginator.Next(operationContext) {
+		page := paginator.CurrentPage()
+
+		if len(page.StackEvents) > 0 {
+			return *page.StackEvents[0].Timestamp, nil
+		}
+	}
+
+	return time.Time{}, paginator.Err()
+}
+
+func getStackEventsPaginator(info data.StackInfo) cloudformation.DescribeStackEventsPaginator {
+	stack := stackIdentifier(info)
+
 	input := cloudformation.DescribeStackEventsInput{
-		StackName: &info.StackID,
+		StackName: &stack,
 	}
 
 	client := getClient()
 
 	req := client.DescribeStackEventsRequest(&input)
 
-	events := cloudformation.NewDescribeStackEventsPaginator(req)
-
-	return events
+	return cloudformation.NewDescribeStackEventsPaginator(req)
 }
 
 // GetStackResources get all the resources that exist ina particular CloudFormation stack
 func GetStackResources(info data.StackInfo) cloudformation.ListStackResourcesPaginator {
+	stack := stackIdentifier(info)
+
 	input := cloudformation.ListStackResourcesInput{
-		StackName: &info.StackName,
+		StackName: &stack,
 	}
 
 	client := getClient()
@@ -304,32 +651,72 @@ func GetStackResources(info data.StackInfo) cloudformation.ListStackResourcesPag
 	return resources
 }
 
-// VerifyAWSCredentials verifies AWS credentials are properly configured by running a List Stack command and analyzing errors for common issues with credentials
+// ListStacks gets a summary of every stack in the account and region, including deleted ones
+func ListStacks() ([]cloudformation.StackSummary, error) {
+	input := cloudformation.ListStacksInput{}
+
+	client := getClient()
+
+	req := client.ListStacksRequest(&input)
+
+	paginator := cloudformation.NewListStacksPaginator(req)
+
+	stacks := make([]cloudformation.StackSummary, 0)
+
+	for paginator.Next(operationContext) {
+		stacks = append(stacks, paginator.CurrentPage().StackSummaries...)
+	}
+
+	return stacks, paginator.Err()
+}
+
+// VerifyAWSCredentials verifies AWS credentials are properly configured before any other call. A missing region is reported
+// first, then sts:GetCallerIdentity, which needs no permissions, tells missing and expired credentials apart, and finally a
+// ListStacks call checks the identity may use CloudFormation. Each failure is reported with what to set to fix it
 func VerifyAWSCredentials() error {
+	// load the configuration up front, so a missing profile is reported instead of failing when the client is created
+	cfg, err := loadConfig()
+	if err != nil {
+		return handleCredentialsError(err)
+	}
+
+	if cfg.Region == "" {
+		return missingRegionError()
+	}
+
+	identity, err := CallerIdentity()
+	if err != nil {
+		return handleCredentialsError(err)
+	}
+
 	input := cloudformation.ListStacksInput{}
 
 	client := getClient()
 
 	req := client.ListStacksRequest(&input)
 
-	_, err := req.Send(context.Background())
+	_, err = req.Send(operationContext)
 	if err != nil {
-		err = handleCredentialsError(err)
+		if IsAccessDenied(err) {
+			return insufficientPermissionsError(identity)
+		}
 
-		return err
+		return handleCredentialsError(err)
+	}
+
+	if len(assumeRoleChain) > 0 {
+		colors.Notice(colors.Status("Assumed " + identity))
 	}
 
 	return nil
 }
 
-func handleCredentialsError(err error) error {
-	strErr := err.Error()
-	var msg string
-
-	if strings.Contains(strErr, unknownEndpoint) {
-		msg = colors.Error("Unable to verify AWS credentials. Ensure your configuration is correct. \n")
-		msg += colors.Docs("https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-files.html")
-	}
+// IsStackNotFound determines if a request failed because the stack doesn't exist
+func IsStackNotFound(err error) bool {
+	return err != nil && strings.Contains(err.Error(), stackNotFound)
+}
 
-	return errors.New(msg)
+// IsAccessDenied determines if a request failed because the caller's IAM policy doesn't allow it
+func IsAccessDenied(err error) bool {
+	return err != nil && strings.Contains(err.Error(), accessDenied)
 }
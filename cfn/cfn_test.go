@@ -0,0 +1,352 @@
+package cfn
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/defaults"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/data"
+)
+
+const (
+	testStackName string = "cirrus-test"
+	testStackID   string = "arn:aws:cloudformation:us-east-1:123456789012:stack/cirrus-test/0f2c5e50-5d1b-11ea-8f2c-0a1b2c3d4e5f"
+)
+
+// useTestServer points the CloudFormation client at a local server that answers every call with handler, given the decoded
+// query of the request, until the test ends
+func useTestServer(t *testing.T, handler func(w http.ResponseWriter, query url.Values)) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Fatalf("unable to parse request: %s", err)
+		}
+
+		handler(w, r.PostForm)
+	}))
+
+	cfg := defaults.Config()
+	cfg.Region = "us-east-1"
+	cfg.Credentials = aws.NewStaticCredentialsProvider("AKID", "SECRET", "")
+	cfg.EndpointResolver = aws.ResolveWithEndpointURL(server.URL)
+	cfg.Retryer = newRetryer()
+
+	cfnClient = cloudformation.New(cfg)
+	InvalidateStackCache()
+
+	t.Cleanup(func() {
+		server.Close()
+		cfnClient = nil
+		InvalidateStackCache()
+	})
+}
+
+// writeError answers a call with an AWS query error
+func writeError(w http.ResponseWriter, status int, code string, message string) {
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<ErrorResponse><Error><Type>Sender</Type><Code>%s</Code><Message>%s</Message></Error><RequestId>test</RequestId></ErrorResponse>`, code, message)
+}
+
+// writeResult answers a call of action with the XML of its result
+func writeResult(w http.ResponseWriter, action string, result string) {
+	fmt.Fprintf(w, `<%[1]sResponse><%[1]sResult>%[2]s</%[1]sResult><ResponseMetadata><RequestId>test</RequestId></ResponseMetadata></%[1]sResponse>`, action, result)
+}
+
+// stackEvent renders a stack event of the test stack as a result member. Events of the stack itself carry its ID as physical ID
+func stackEvent(id string, logicalID string, resourceType string, status cloudformation.ResourceStatus, timestamp string) string {
+	physicalID := logicalID + "-physical"
+	if logicalID == testStackName {
+		physicalID = testStackID
+	}
+
+	return fmt.Sprintf(`<member><EventId>%s</EventId><StackName>%s</StackName><StackId>%s</StackId><LogicalResourceId>%s</LogicalResourceId>`+
+		`<PhysicalResourceId>%s</PhysicalResourceId><ResourceType>%s</ResourceType><ResourceStatus>%s</ResourceStatus><Timestamp>%s</Timestamp></member>`,
+		id, testStackName, testStackID, logicalID, physicalID, resourceType, status, timestamp)
+}
+
+func TestGetStackEventsPollsByIDOnceTheNameIsGone(t *testing.T) {
+	polledWith := make([]string, 0)
+
+	useTestServer(t, func(w http.ResponseWriter, query url.Values) {
+		if query.Get("Action") != "DescribeStackEvents" {
+			t.Fatalf("unexpected call %s", query.Get("Action"))
+		}
+
+		stackName := query.Get("StackName")
+		polledWith = append(polledWith, stackName)
+
+		// a deleted stack can no longer be resolved by name
+		if stackName != testStackID {
+			writeError(w, http.StatusBadRequest, "ValidationError", fmt.Sprintf("Stack with id %s does not exist", stackName))
+			return
+		}
+
+		writeResult(w, "DescribeStackEvents", "<StackEvents>"+
+			stackEvent("3", testStackName, "AWS::CloudFormation::Stack", cloudformation.ResourceStatusDeleteComplete, "2020-03-01T10:02:00Z")+
+			stackEvent("2", "Bucket", "AWS::S3::Bucket", cloudformation.ResourceStatusDeleteComplete, "2020-03-01T10:01:00Z")+
+			stackEvent("1", testStackName, "AWS::CloudFormation::Stack", cloudformation.ResourceStatusDeleteInProgress, "2020-03-01T10:00:00Z")+
+			"</StackEvents>")
+	})
+
+	// the ID the down path captured before deleting
+	info := data.StackInfo{StackName: testStackName, StackID: testStackID}
+	since := time.Date(2020, 3, 1, 10, 0, 30, 0, time.UTC)
+
+	events, err := GetStackEvents(info, since, 0)
+	if err != nil {
+		t.Fatalf("polling the events of a deleted stack failed: %s", err)
+	}
+
+	if len(events) != 2 || events[0].ResourceStatus != cloudformation.ResourceStatusDeleteComplete {
+		t.Errorf("expected the two events since the cutoff, newest first, got %v", events)
+	}
+
+	for _, stackName := range polledWith {
+		if stackName != testStackID {
+			t.Errorf("expected events to be polled by stack ID, polled with %s", stackName)
+		}
+	}
+
+	// without the ID, the name is all there is to poll with, and a deleted stack can't be found by it
+	polledWith = polledWith[:0]
+
+	if _, err := GetStackEvents(data.StackInfo{StackName: testStackName}, since, 0); err == nil {
+		t.Errorf("expected polling a deleted stack by name to fail")
+	}
+
+	if len(polledWith) != 1 || polledWith[0] != testStackName {
+		t.Errorf("expected a stack without an ID to be polled by name, polled with %q", polledWith)
+	}
+}
+
+func TestCreateChangesDistinguishesNoChangesFromFailures(t *testing.T) {
+	tests := []struct {
+		name      string
+		reason    string
+		noChanges bool
+	}{
+		{
+			name:      "no changes",
+			reason:    "The submitted information didn't contain changes. Submit different information to create a change set.",
+			noChanges: true,
+		},
+		{
+			name:      "no updates",
+			reason:    "No updates are to be performed.",
+			noChanges: true,
+		},
+		{
+			name:   "invalid property",
+			reason: "Template format error: Unresolved resource dependencies [Missing] in the Resources block of the template",
+		},
+		{
+			name:   "unsupported property",
+			reason: "Encountered unsupported property BucketNam",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useTestServer(t, func(w http.ResponseWriter, query url.Values) {
+				switch query.Get("Action") {
+				case "CreateChangeSet":
+					writeResult(w, "CreateChangeSet", "<Id>change-set</Id><StackId>"+testStackID+"</StackId>")
+				case "DescribeChangeSet":
+					writeResult(w, "DescribeChangeSet", "<ChangeSetName>cirrus-test</ChangeSetName><Status>FAILED</Status>"+
+						"<ExecutionStatus>UNAVAILABLE</ExecutionStatus><StatusReason>"+xmlEscape(test.reason)+"</StatusReason>")
+				default:
+					t.Fatalf("unexpected call %s", query.Get("Action"))
+				}
+			})
+
+			info := data.StackInfo{StackName: testStackName, ChangeSetName: "cirrus-test"}
+
+			_, err := CreateChanges(info, []byte("Resources: {}"), nil, nil, true, ChangeSetOptions{})
+
+			var failure *ChangeSetFailedError
+			if !errors.As(err, &failure) {
+				t.Fatalf("expected a ChangeSetFailedError, got %v", err)
+			}
+
+			if failure.Reason != test.reason {
+				t.Errorf("expected the full status reason %q, got %q", test.reason, failure.Reason)
+			}
+
+			if failure.NoChanges() != test.noChanges {
+				t.Errorf("expected NoChanges to be %t for %q", test.noChanges, test.reason)
+			}
+		})
+	}
+}
+
+func xmlEscape(text string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(text))
+
+	return escaped.String()
+}
+
+func TestVerifyExecutable(t *testing.T) {
+	statuses := []cloudformation.ExecutionStatus{
+		cloudformation.ExecutionStatusUnavailable,
+		cloudformation.ExecutionStatusExecuteInProgress,
+		cloudformation.ExecutionStatusExecuteComplete,
+		cloudformation.ExecutionStatusExecuteFailed,
+		cloudformation.ExecutionStatusObsolete,
+	}
+
+	for _, status := range statuses {
+		t.Run(string(status), func(t *testing.T) {
+			err := VerifyExecutable(&cloudformation.DescribeChangeSetOutput{
+				ChangeSetName:   aws.String("cirrus-test"),
+				ExecutionStatus: status,
+				StatusReason:    aws.String("reason"),
+			})
+
+			var notExecutable *ChangeSetNotExecutableError
+			if !errors.As(err, &notExecutable) {
+				t.Fatalf("expected a ChangeSetNotExecutableError, got %v", err)
+			}
+
+			if notExecutable.ExecutionStatus != status || notExecutable.Reason != "reason" {
+				t.Errorf("expected the error to carry status %s and its reason, got %+v", status, notExecutable)
+			}
+
+			if !strings.Contains(err.Error(), string(status)) {
+				t.Errorf("expected the message to name status %s, got %s", status, err)
+			}
+		})
+	}
+
+	err := VerifyExecutable(&cloudformation.DescribeChangeSetOutput{
+		ChangeSetName:   aws.String("cirrus-test"),
+		ExecutionStatus: cloudformation.ExecutionStatusAvailable,
+	})
+	if err != nil {
+		t.Errorf("expected an available change set to be executable, got %s", err)
+	}
+}
+
+func TestDetermineIfStackExistsForMissingStack(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, query url.Values) {
+		writeError(w, http.StatusBadRequest, "ValidationError", fmt.Sprintf("Stack with id %s does not exist", query.Get("StackName")))
+	})
+
+	exists, err := DetermineIfStackExists(testStackName)
+	if err != nil {
+		t.Fatalf("expected a missing stack not to be an error, got %s", err)
+	}
+
+	if exists {
+		t.Errorf("expected the stack not to exist")
+	}
+
+	_, err = GetStack(testStackName)
+	if !IsStackNotFound(err) {
+		t.Errorf("expected a not found error, got %v", err)
+	}
+}
+
+func TestGetOperationEventsAcrossPages(t *testing.T) {
+	pages := map[string]string{
+		"": "<StackEvents>" +
+			stackEvent("5", testStackName, "AWS::CloudFormation::Stack", cloudformation.ResourceStatusUpdateComplete, "2020-03-01T10:04:00Z") +
+			stackEvent("4", "Bucket", "AWS::S3::Bucket", cloudformation.ResourceStatusUpdateComplete, "2020-03-01T10:03:00Z") +
+			"</StackEvents><NextToken>second</NextToken>",
+		// the operation started on the second page, after events of the operation before it
+		"second": "<StackEvents>" +
+			stackEvent("3", "Bucket", "AWS::S3::Bucket", cloudformation.ResourceStatusUpdateInProgress, "2020-03-01T10:02:00Z") +
+			stackEvent("2", testStackName, "AWS::CloudFormation::Stack", cloudformation.ResourceStatusUpdateInProgress, "2020-03-01T10:01:00Z") +
+			"</StackEvents><NextToken>third</NextToken>",
+		"third": "<StackEvents>" +
+			stackEvent("1", testStackName, "AWS::CloudFormation::Stack", cloudformation.ResourceStatusCreateComplete, "2020-02-01T10:00:00Z") +
+			"</StackEvents>",
+	}
+
+	fetched := make([]string, 0)
+
+	useTestServer(t, func(w http.ResponseWriter, query url.Values) {
+		token := query.Get("NextToken")
+		fetched = append(fetched, token)
+
+		page, ok := pages[token]
+		if !ok {
+			t.Fatalf("unexpected page %q", token)
+		}
+
+		writeResult(w, "DescribeStackEvents", page)
+	})
+
+	events, err := GetOperationEvents(data.StackInfo{StackName: testStackName, StackID: testStackID}, 0)
+	if err != nil {
+		t.Fatalf("unable to get the operation's events: %s", err)
+	}
+
+	ids := make([]string, 0)
+	for _, event := range events {
+		ids = append(ids, *event.EventId)
+	}
+
+	if strings.Join(ids, ",") != "5,4,3,2" {
+		t.Errorf("expected the events of the operation back to its start, got %v", ids)
+	}
+
+	if len(fetched) != 2 {
+		t.Errorf("expected paging to stop at the page holding the operation's start, fetched %q", fetched)
+	}
+}
+
+func TestCreateChangeSetTags(t *testing.T) {
+	tests := []struct {
+		name     string
+		tags     []cloudformation.Tag
+		replace  bool
+		expected url.Values
+	}{
+		// leaving tags out keeps the stack's tags on an update
+		{name: "no tags", expected: url.Values{}},
+		// an empty list removes them
+		{name: "replacing with no tags", replace: true, expected: url.Values{"Tags": {""}}},
+		{
+			name:     "tags",
+			tags:     []cloudformation.Tag{{Key: aws.String("team"), Value: aws.String("cirrus")}},
+			expected: url.Values{"Tags.member.1.Key": {"team"}, "Tags.member.1.Value": {"cirrus"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			sent := url.Values{}
+
+			useTestServer(t, func(w http.ResponseWriter, query url.Values) {
+				for key, values := range query {
+					if strings.HasPrefix(key, "Tags") {
+						sent[key] = values
+					}
+				}
+
+				writeResult(w, "CreateChangeSet", "<Id>change-set</Id><StackId>"+testStackID+"</StackId>")
+			})
+
+			info := data.StackInfo{StackName: testStackName, ChangeSetName: "cirrus-test"}
+
+			err := createChangeSet(info, []byte("Resources: {}"), test.tags, nil, true, ChangeSetOptions{ReplaceTags: test.replace})
+			if err != nil {
+				t.Fatalf("unable to create the change set: %s", err)
+			}
+
+			if !reflect.DeepEqual(sent, test.expected) {
+				t.Errorf("expected the tags sent to be %v, got %v", test.expected, sent)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package cfn
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/awserr"
+	"github.com/aws/aws-sdk-go-v2/aws/external"
+	"github.com/aws/aws-sdk-go-v2/aws/stscreds"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/blueseph/cirrus/colors"
+)
+
+const roleSessionName string = "cirrus"
+
+// missingCredentialsCodes are the SDK error codes of a credential chain that found no credentials at all
+var missingCredentialsCodes = []string{"NoCredentialProviders", "EC2RoleRequestError"}
+
+// expiredCredentialsCodes are the error codes AWS returns for credentials it found but rejected, usually because a session expired
+var expiredCredentialsCodes = []string{"ExpiredToken", "ExpiredTokenException", "RequestExpired", "InvalidClientTokenId", "UnrecognizedClientException", "SignatureDoesNotMatch"}
+
+// assumeRoleChain is the roles assumed in order before any call, each using the credentials of the one before it
+var assumeRoleChain []string
+
+// region overrides the region of the default AWS configuration when set
+var region string
+
+// SetRegion sets the region every AWS call is made in, overriding AWS_REGION and the shared config. An empty region keeps the default
+func SetRegion(name string) {
+	region = name
+	cfnClient = nil
+}
+
+// profile selects a named profile of the shared config when set
+var profile string
+
+// SetProfile sets the named profile of the shared config to load credentials and settings from, overriding AWS_PROFILE.
+// An empty profile keeps the default
+func SetProfile(name string) {
+	profile = name
+	cfnClient = nil
+}
+
+// SetAssumeRoleChain sets the roles to assume in order, each with the credentials of the previous one, before making any AWS call
+func SetAssumeRoleChain(roleARNs []string) {
+	assumeRoleChain = roleARNs
+	cfnClient = nil
+}
+
+// loadConfig loads the default AWS configuration from any selected profile, applies any region override, and assumes each role
+// of the chain in turn
+func loadConfig() (aws.Config, error) {
+	configs := make([]external.Config, 0)
+	if profile != "" {
+		configs = append(configs, external.WithSharedConfigProfile(profile))
+	}
+
+	cfg, err := external.LoadDefaultAWSConfig(configs...)
+	if err != nil {
+		return cfg, err
+	}
+
+	if region != "" {
+		cfg.Region = region
+	}
+
+	cfg.Retryer = newRetryer()
+
+	for _, roleARN := range assumeRoleChain {
+		// the STS client keeps the credentials of the previous hop
+		client := sts.New(cfg)
+
+		cfg.Credentials = stscreds.NewAssumeRoleProvider(client, roleARN, func(options *stscreds.AssumeRoleProviderOptions) {
+			options.RoleSessionName = roleSessionName
+		})
+	}
+
+	return cfg, nil
+}
+
+// CallerIdentity returns the ARN of the identity cirrus makes calls as, after assuming any role chain
+func CallerIdentity() (string, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return "", err
+	}
+
+	req := sts.New(cfg).GetCallerIdentityRequest(&sts.GetCallerIdentityInput{})
+
+	identity, err := req.Send(operationContext)
+	if err != nil {
+		return "", err
+	}
+
+	return *identity.Arn, nil
+}
+
+// errorCode returns the AWS error code of an error, or an empty string for errors that didn't come from AWS or the SDK
+func errorCode(err error) string {
+	var awsErr awserr.Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Code()
+	}
+
+	return ""
+}
+
+func containsCode(codes []string, code string) bool {
+	for _, c := range codes {
+		if c == code {
+			return true
+		}
+	}
+
+	return false
+}
+
+// handleCredentialsError explains why AWS credentials couldn't be verified, with what to set to fix it
+func handleCredentialsError(err error) error {
+	strErr := err.Error()
+	code := errorCode(err)
+
+	var msg string
+
+	switch {
+	case containsCode(missingCredentialsCodes, code):
+		source := "Set AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY, or choose a profile of the shared config with AWS_PROFILE or --profile"
+		if profile != "" {
+			source = fmt.Sprintf("Add credentials to profile %s in ~/.aws/credentials or ~/.aws/config, or choose another profile with --profile", profile)
+		}
+
+		msg = colors.Error(fmt.Sprintf("No AWS credentials were found. %s. \n", source))
+		msg += colors.Docs("https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-envvars.html")
+	case containsCode(expiredCredentialsCodes, code):
+		msg = colors.Error(fmt.Sprintf("AWS rejected your credentials as expired or invalid (%s). Refresh them, e.g. with aws sso login or a new AWS_SESSION_TOKEN, "+
+			"and check AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY or the profile set by AWS_PROFILE or --profile. \n", code))
+		msg += colors.Docs("https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-files.html")
+	case len(assumeRoleChain) > 0:
+		msg = colors.Error(fmt.Sprintf("Unable to assume role chain %s: %s \n", strings.Join(assumeRoleChain, " -> "), strErr))
+		msg += colors.Docs("https://docs.aws.amazon.com/IAM/latest/UserGuide/id_roles_terms-and-concepts.html#iam-term-role-chaining")
+	case profile != "":
+		msg = colors.Error(fmt.Sprintf("Unable to verify AWS credentials of profile %s. Ensure the profile exists and its credentials haven't expired: %s \n", profile, strErr))
+		msg += colors.Docs("https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-profiles.html")
+	case strings.Contains(strErr, unknownEndpoint):
+		msg = colors.Error(fmt.Sprintf("Unable to reach AWS in region %s. Ensure AWS_REGION or --region names a valid region. \n", region))
+		msg += colors.Docs("https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-files.html")
+	default:
+		msg = colors.Error(fmt.Sprintf("Unable to verify AWS credentials. Ensure your configuration is correct: %s \n", strErr))
+		msg += colors.Docs("https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-files.html")
+	}
+
+	return errors.New(msg)
+}
+
+// missingRegionError explains that no region was found in the flags, the environment or the shared config
+func missingRegionError() error {
+	msg := colors.Error("No AWS region is set. Set AWS_REGION, pass --region, or set region in the profile of the shared config. \n")
+	msg += colors.Docs("https://docs.aws.amazon.com/cli/latest/userguide/cli-configure-region.html")
+
+	return errors.New(msg)
+}
+
+// insufficientPermissionsError explains that the credentials work but their identity may not use CloudFormation
+func insufficientPermissionsError(identity string) error {
+	msg := colors.Error(fmt.Sprintf("Your AWS credentials are valid, but %s is not allowed to call cloudformation:ListStacks. "+
+		"Attach a policy allowing CloudFormation, or choose another identity with AWS_PROFILE, --profile or --assume-role-arn. \n", identity))
+	msg += colors.Docs("https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/using-iam-template.html")
+
+	return errors.New(msg)
+}
@@ -0,0 +1,71 @@
+package cfn
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/blueseph/cirrus/data"
+)
+
+// notFoundCodes are the error codes the supported services return when a resource doesn't exist
+var notFoundCodes = []string{
+	"NotFound",
+	"NoSuchBucket",
+	"ResourceNotFoundException",
+}
+
+// ResourceExists checks whether the resource an import candidate names already exists, by describing it with its service
+func ResourceExists(candidate data.ImportCandidate) (bool, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return false, err
+	}
+
+	switch candidate.ResourceType {
+	case "AWS::S3::Bucket":
+		req := s3.New(cfg).HeadBucketRequest(&s3.HeadBucketInput{Bucket: &candidate.Identifier})
+		_, err = req.Send(operationContext)
+	case "AWS::DynamoDB::Table":
+		req := dynamodb.New(cfg).DescribeTableRequest(&dynamodb.DescribeTableInput{TableName: &candidate.Identifier})
+		_, err = req.Send(operationContext)
+	case "AWS::Logs::LogGroup":
+		return logGroupExists(cloudwatchlogs.New(cfg), candidate.Identifier)
+	default:
+		return false, nil
+	}
+
+	if err != nil && isNotFound(err) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// logGroupExists looks for an exact match, since log groups can only be described by prefix
+func logGroupExists(client *cloudwatchlogs.Client, name string) (bool, error) {
+	req := client.DescribeLogGroupsRequest(&cloudwatchlogs.DescribeLogGroupsInput{LogGroupNamePrefix: &name})
+
+	paginator := cloudwatchlogs.NewDescribeLogGroupsPaginator(req)
+
+	for paginator.Next(operationContext) {
+		for _, group := range paginator.CurrentPage().LogGroups {
+			if group.LogGroupName != nil && *group.LogGroupName == name {
+				return true, nil
+			}
+		}
+	}
+
+	return false, paginator.Err()
+}
+
+func isNotFound(err error) bool {
+	for _, code := range notFoundCodes {
+		if strings.Contains(err.Error(), code) {
+			return true
+		}
+	}
+
+	return false
+}
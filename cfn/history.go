@@ -0,0 +1,112 @@
+package cfn
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+)
+
+const cloudformationEventSource string = "cloudformation.amazonaws.com"
+
+// cloudTrailUnavailableCodes are the error codes CloudTrail returns when its event history can't be read in the account or region
+var cloudTrailUnavailableCodes = []string{"CloudTrailAccessNotEnabledException", "OptInRequired", "SubscriptionRequiredException"}
+
+// GetDeployHistory looks up the changes made to a stack since a time in CloudTrail's event history, newest first, stopping
+// once limit changes are found. Events are looked up by the stack's name and ID as the resource name, since CloudTrail records
+// either depending on the call. It needs the cloudtrail:LookupEvents permission
+func GetDeployHistory(info data.StackInfo, since time.Time, limit int) ([]data.HistoryEntry, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	client := cloudtrail.New(cfg)
+
+	resourceNames := []string{info.StackName}
+	if info.StackID != "" && info.StackID != info.StackName {
+		resourceNames = append(resourceNames, info.StackID)
+	}
+
+	events := make([]cloudtrail.Event, 0)
+	seen := make(map[string]bool)
+
+	for _, resourceName := range resourceNames {
+		found, err := lookupStackEvents(client, resourceName, info, since, limit)
+		if err != nil {
+			return nil, handleCloudTrailError(err)
+		}
+
+		for _, event := range found {
+			if event.EventId != nil && seen[*event.EventId] {
+				continue
+			}
+
+			if event.EventId != nil {
+				seen[*event.EventId] = true
+			}
+
+			events = append(events, event)
+		}
+	}
+
+	entries := data.DeployHistory(events, info)
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	return entries, nil
+}
+
+// lookupStackEvents pages through the events recorded against a resource name, which CloudTrail filters on rather than cirrus
+// reading every CloudFormation call in the account. Events are still checked to be CloudFormation changes of the stack, and pages
+// stop once limit changes are found, as CloudTrail returns events newest first
+func lookupStackEvents(client *cloudtrail.Client, resourceName string, info data.StackInfo, since time.Time, limit int) ([]cloudtrail.Event, error) {
+	req := client.LookupEventsRequest(&cloudtrail.LookupEventsInput{
+		StartTime: aws.Time(since),
+		LookupAttributes: []cloudtrail.LookupAttribute{
+			{
+				AttributeKey:   cloudtrail.LookupAttributeKeyResourceName,
+				AttributeValue: aws.String(resourceName),
+			},
+		},
+	})
+
+	paginator := cloudtrail.NewLookupEventsPaginator(req)
+	events := make([]cloudtrail.Event, 0)
+	changes := 0
+
+	for changes < limit && paginator.Next(operationContext) {
+		page := make([]cloudtrail.Event, 0)
+
+		// other services may record a resource of the same name
+		for _, event := range paginator.CurrentPage().Events {
+			if event.EventSource != nil && *event.EventSource == cloudformationEventSource {
+				page = append(page, event)
+			}
+		}
+
+		events = append(events, page...)
+		changes += len(data.DeployHistory(page, info))
+	}
+
+	return events, paginator.Err()
+}
+
+// handleCloudTrailError explains a failed event history lookup, which needs permissions deploying doesn't
+func handleCloudTrailError(err error) error {
+	switch {
+	case IsAccessDenied(err):
+		return errors.New(colors.Error("Reading deploy history requires the cloudtrail:LookupEvents permission, which the current identity doesn't have"))
+	case containsCode(cloudTrailUnavailableCodes, errorCode(err)):
+		msg := colors.Error("CloudTrail event history isn't available in this account or region") + "\n"
+		msg += colors.Docs("https://docs.aws.amazon.com/awscloudtrail/latest/userguide/view-cloudtrail-events.html")
+
+		return errors.New(msg)
+	}
+
+	return err
+}
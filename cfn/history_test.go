@@ -0,0 +1,106 @@
+package cfn
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/defaults"
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+	"github.com/blueseph/cirrus/data"
+)
+
+// lookupRequest is the part of a LookupEvents request the test server checks
+type lookupRequest struct {
+	LookupAttributes []struct {
+		AttributeKey   string
+		AttributeValue string
+	}
+	NextToken string
+}
+
+// trailEvent renders a CloudTrail event of source changing the test stack
+func trailEvent(id string, source string, name string, readOnly bool, timestamp int64) map[string]interface{} {
+	record := fmt.Sprintf(`{"userIdentity": {"arn": "arn:aws:iam::123456789012:user/deployer"}, "requestParameters": {"stackName": %q}}`, testStackName)
+
+	return map[string]interface{}{
+		"EventId":         id,
+		"EventSource":     source,
+		"EventName":       name,
+		"EventTime":       timestamp,
+		"ReadOnly":        fmt.Sprint(readOnly),
+		"CloudTrailEvent": record,
+	}
+}
+
+func TestLookupStackEventsFiltersByResourceName(t *testing.T) {
+	pages := map[string]map[string]interface{}{
+		"": {
+			"Events": []interface{}{
+				trailEvent("4", cloudformationEventSource, "DescribeStackEvents", true, 1583056800),
+				trailEvent("3", cloudformationEventSource, "ExecuteChangeSet", false, 1583056700),
+				trailEvent("2", "s3.amazonaws.com", "PutBucketPolicy", false, 1583056600),
+			},
+			"NextToken": "second",
+		},
+		"second": {
+			"Events":    []interface{}{trailEvent("1", cloudformationEventSource, "CreateChangeSet", false, 1583056500)},
+			"NextToken": "third",
+		},
+	}
+
+	fetched := make([]string, 0)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request lookupRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatalf("unable to decode the request: %s", err)
+		}
+
+		if len(request.LookupAttributes) != 1 || request.LookupAttributes[0].AttributeKey != "ResourceName" ||
+			request.LookupAttributes[0].AttributeValue != testStackName {
+			t.Errorf("expected events to be looked up by the stack's resource name, got %+v", request.LookupAttributes)
+		}
+
+		fetched = append(fetched, request.NextToken)
+
+		page, ok := pages[request.NextToken]
+		if !ok {
+			t.Fatalf("unexpected page %q", request.NextToken)
+		}
+
+		json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	cfg := defaults.Config()
+	cfg.Region = "us-east-1"
+	cfg.Credentials = aws.NewStaticCredentialsProvider("AKID", "SECRET", "")
+	cfg.EndpointResolver = aws.ResolveWithEndpointURL(server.URL)
+
+	info := data.StackInfo{StackName: testStackName}
+
+	events, err := lookupStackEvents(cloudtrail.New(cfg), testStackName, info, time.Unix(0, 0), 2)
+	if err != nil {
+		t.Fatalf("unable to look up events: %s", err)
+	}
+
+	for _, event := range events {
+		if *event.EventSource != cloudformationEventSource {
+			t.Errorf("expected events of other services to be dropped, got %s", *event.EventName)
+		}
+	}
+
+	entries := data.DeployHistory(events, info)
+	if len(entries) != 2 || entries[0].Operation != "ExecuteChangeSet" || entries[1].Operation != "CreateChangeSet" {
+		t.Errorf("expected the two changes newest first, got %+v", entries)
+	}
+
+	if len(fetched) != 2 {
+		t.Errorf("expected paging to stop once the limit of changes was found, fetched %q", fetched)
+	}
+}
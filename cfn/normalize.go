@@ -0,0 +1,95 @@
+package cfn
+
+import (
+	"encoding/json"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NormalizeTemplate parses a JSON or YAML template and re-serializes it canonically as JSON with sorted keys and no
+// whitespace, expanding short form intrinsic functions (!Ref, !Sub) to their long form, so semantically equal templates compare equal
+func NormalizeTemplate(body string) (string, error) {
+	var document yaml.Node
+
+	if err := yaml.Unmarshal([]byte(body), &document); err != nil {
+		return "", err
+	}
+
+	if len(document.Content) == 0 {
+		return "", nil
+	}
+
+	value, err := normalizeNode(document.Content[0])
+	if err != nil {
+		return "", err
+	}
+
+	normalized, err := json.Marshal(value)
+
+	return string(normalized), err
+}
+
+func normalizeNode(node *yaml.Node) (interface{}, error) {
+	var value interface{}
+
+	switch node.Kind {
+	case yaml.AliasNode:
+		return normalizeNode(node.Alias)
+	case yaml.MappingNode:
+		mapping := make(map[string]interface{})
+
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			child, err := normalizeNode(node.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+
+			mapping[node.Content[i].Value] = child
+		}
+
+		value = mapping
+	case yaml.SequenceNode:
+		sequence := make([]interface{}, 0)
+
+		for _, item := range node.Content {
+			child, err := normalizeNode(item)
+			if err != nil {
+				return nil, err
+			}
+
+			sequence = append(sequence, child)
+		}
+
+		value = sequence
+	default:
+		if isShortFormFunction(node.Tag) {
+			value = node.Value
+		} else if err := node.Decode(&value); err != nil {
+			return nil, err
+		}
+	}
+
+	if isShortFormFunction(node.Tag) {
+		return expandShortFormFunction(strings.TrimPrefix(node.Tag, "!"), value), nil
+	}
+
+	return value, nil
+}
+
+func isShortFormFunction(tag string) bool {
+	return strings.HasPrefix(tag, "!") && !strings.HasPrefix(tag, "!!")
+}
+
+func expandShortFormFunction(name string, value interface{}) interface{} {
+	if name == "Ref" || name == "Condition" {
+		return map[string]interface{}{name: value}
+	}
+
+	// !GetAtt Resource.Attribute is the short form of a two element list
+	if attribute, ok := value.(string); ok && name == "GetAtt" {
+		value = strings.SplitN(attribute, ".", 2)
+	}
+
+	return map[string]interface{}{"Fn::" + name: value}
+}
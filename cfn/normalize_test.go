@@ -0,0 +1,112 @@
+package cfn
+
+import (
+	"testing"
+)
+
+const normalizeJSONTemplate = `{"Resources": {"Bucket": {"Type": "AWS::S3::Bucket", "Properties": {"BucketName": {"Ref": "Name"}, "Tags": [{"Key": "team", "Value": "cirrus"}]}}},
+"Parameters": {"Name": {"Type": "String", "Default": "logs"}}}`
+
+func TestNormalizeTemplateEqualForReformattedTemplates(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{
+			name: "JSON with reordered keys and whitespace",
+			body: `{
+    "Parameters": { "Name": { "Default": "logs", "Type": "String" } },
+    "Resources": {
+        "Bucket": {
+            "Properties": {
+                "Tags": [ { "Value": "cirrus", "Key": "team" } ],
+                "BucketName": { "Ref": "Name" }
+            },
+            "Type": "AWS::S3::Bucket"
+        }
+    }
+}`,
+		},
+		{
+			name: "YAML with long form functions",
+			body: `
+Parameters:
+  Name:
+    Type: String
+    Default: logs
+Resources:
+  Bucket:
+    Type: AWS::S3::Bucket
+    Properties:
+      BucketName:
+        Ref: Name
+      Tags:
+        - Key: team
+          Value: cirrus
+`,
+		},
+		{
+			name: "YAML with short form functions and flow style",
+			body: `
+Resources:
+  Bucket:
+    Properties: {Tags: [{Key: team, Value: cirrus}], BucketName: !Ref Name}
+    Type: "AWS::S3::Bucket"
+Parameters: {Name: {Default: 'logs', Type: String}}
+`,
+		},
+	}
+
+	expected, err := NormalizeTemplate(normalizeJSONTemplate)
+	if err != nil {
+		t.Fatalf("unable to normalize the reference template: %s", err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			normalized, err := NormalizeTemplate(test.body)
+			if err != nil {
+				t.Fatalf("unable to normalize: %s", err)
+			}
+
+			if normalized != expected {
+				t.Errorf("expected %s, got %s", expected, normalized)
+			}
+		})
+	}
+}
+
+func TestNormalizeTemplateDiffersForChangedTemplates(t *testing.T) {
+	changed := `{"Resources": {"Bucket": {"Type": "AWS::S3::Bucket", "Properties": {"BucketName": {"Ref": "Name"}, "Tags": [{"Key": "team", "Value": "other"}]}}},
+"Parameters": {"Name": {"Type": "String", "Default": "logs"}}}`
+
+	original, err := NormalizeTemplate(normalizeJSONTemplate)
+	if err != nil {
+		t.Fatalf("unable to normalize: %s", err)
+	}
+
+	normalized, err := NormalizeTemplate(changed)
+	if err != nil {
+		t.Fatalf("unable to normalize: %s", err)
+	}
+
+	if normalized == original {
+		t.Errorf("expected a changed tag value to normalize differently, both were %s", normalized)
+	}
+}
+
+func TestNormalizeTemplateExpandsGetAtt(t *testing.T) {
+	short, err := NormalizeTemplate("Value: !GetAtt Bucket.Arn")
+	if err != nil {
+		t.Fatalf("unable to normalize: %s", err)
+	}
+
+	long, err := NormalizeTemplate(`{"Value": {"Fn::GetAtt": ["Bucket", "Arn"]}}`)
+	if err != nil {
+		t.Fatalf("unable to normalize: %s", err)
+	}
+
+	if short != long {
+		t.Errorf("expected !GetAtt to expand to %s, got %s", long, short)
+	}
+}
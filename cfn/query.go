@@ -0,0 +1,34 @@
+package cfn
+
+import (
+	"io/ioutil"
+	"net/url"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// withQueryParameter returns a build handler that adds a parameter to an encoded query request body.
+// It's used for API fields that are newer than the SDK, so the SDK can't serialize them itself
+func withQueryParameter(key string, value string) func(*aws.Request) {
+	return func(r *aws.Request) {
+		if r.Error != nil || r.Body == nil {
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			r.Error = err
+			return
+		}
+
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			r.Error = err
+			return
+		}
+
+		values.Set(key, value)
+
+		r.SetBufferBody([]byte(values.Encode()))
+	}
+}
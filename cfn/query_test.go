@@ -0,0 +1,47 @@
+package cfn
+
+import (
+	"io/ioutil"
+	"net/url"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/defaults"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+func TestWithQueryParameterAddsImportExistingResources(t *testing.T) {
+	cfg := defaults.Config()
+	cfg.Region = "us-east-1"
+	cfg.Credentials = aws.NewStaticCredentialsProvider("AKID", "SECRET", "")
+
+	req := cloudformation.New(cfg).CreateChangeSetRequest(&cloudformation.CreateChangeSetInput{
+		ChangeSetName: aws.String("cirrus-test"),
+		StackName:     aws.String(testStackName),
+		TemplateBody:  aws.String("Resources: {}"),
+	})
+	req.Handlers.Build.PushBack(withQueryParameter("ImportExistingResources", "true"))
+
+	if err := req.Build(); err != nil {
+		t.Fatalf("unable to build the request: %s", err)
+	}
+
+	body, err := ioutil.ReadAll(req.GetBody())
+	if err != nil {
+		t.Fatalf("unable to read the request body: %s", err)
+	}
+
+	query, err := url.ParseQuery(string(body))
+	if err != nil {
+		t.Fatalf("the request body isn't an encoded query: %s", err)
+	}
+
+	if got := query.Get("ImportExistingResources"); got != "true" {
+		t.Errorf("expected ImportExistingResources=true in the request body, got %q", got)
+	}
+
+	// the fields the SDK serializes itself must survive the rewrite
+	if query.Get("Action") != "CreateChangeSet" || query.Get("StackName") != testStackName || query.Get("ChangeSetName") != "cirrus-test" {
+		t.Errorf("expected the SDK's fields to be kept, got %s", body)
+	}
+}
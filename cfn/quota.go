@@ -0,0 +1,67 @@
+package cfn
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/servicequotas"
+)
+
+const (
+	// DefaultResourceQuota is the CloudFormation resources per stack quota used when Service Quotas doesn't report one
+	DefaultResourceQuota int = 500
+
+	cloudformationServiceCode string = "cloudformation"
+)
+
+// GetResourceQuota gets the CloudFormation resources per stack quota for the account from Service Quotas, preferring an
+// applied quota over the AWS default. It falls back to DefaultResourceQuota when neither is reported
+func GetResourceQuota() (int, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return DefaultResourceQuota, err
+	}
+
+	client := servicequotas.New(cfg)
+	serviceCode := cloudformationServiceCode
+
+	applied := servicequotas.NewListServiceQuotasPaginator(client.ListServiceQuotasRequest(&servicequotas.ListServiceQuotasInput{
+		ServiceCode: &serviceCode,
+	}))
+
+	for applied.Next(operationContext) {
+		if quota, ok := resourcesPerStackQuota(applied.CurrentPage().Quotas); ok {
+			return quota, nil
+		}
+	}
+
+	if applied.Err() != nil {
+		return DefaultResourceQuota, applied.Err()
+	}
+
+	defaults := servicequotas.NewListAWSDefaultServiceQuotasPaginator(client.ListAWSDefaultServiceQuotasRequest(&servicequotas.ListAWSDefaultServiceQuotasInput{
+		ServiceCode: &serviceCode,
+	}))
+
+	for defaults.Next(operationContext) {
+		if quota, ok := resourcesPerStackQuota(defaults.CurrentPage().Quotas); ok {
+			return quota, nil
+		}
+	}
+
+	return DefaultResourceQuota, defaults.Err()
+}
+
+func resourcesPerStackQuota(quotas []servicequotas.ServiceQuota) (int, bool) {
+	for _, quota := range quotas {
+		if quota.QuotaName == nil || quota.Value == nil {
+			continue
+		}
+
+		name := strings.ToLower(*quota.QuotaName)
+		if strings.Contains(name, "resource") && strings.Contains(name, "per stack") {
+			return int(*quota.Value), true
+		}
+	}
+
+	return 0, false
+}
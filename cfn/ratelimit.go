@@ -0,0 +1,86 @@
+package cfn
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+// DefaultAPIRateLimit is how many CloudFormation calls per second cirrus makes at most, unless configured otherwise
+const DefaultAPIRateLimit float64 = 5
+
+// rateLimiter is a token bucket shared by every goroutine making CloudFormation calls. It holds up to one second of calls,
+// and at least one call so a rate below one per second still allows any
+type rateLimiter struct {
+	mutex    sync.Mutex
+	rate     float64
+	capacity float64
+	tokens   float64
+	last     time.Time
+}
+
+// apiRateLimiter paces every CloudFormation call
+var apiRateLimiter = newRateLimiter(DefaultAPIRateLimit)
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	capacity := math.Max(1, perSecond)
+
+	return &rateLimiter{rate: perSecond, capacity: capacity, tokens: capacity, last: time.Now()}
+}
+
+// SetAPIRateLimit sets how many CloudFormation calls per second cirrus makes at most. Zero or less removes the limit
+func SetAPIRateLimit(perSecond float64) {
+	apiRateLimiter = newRateLimiter(perSecond)
+	cfnClient = nil
+}
+
+// wait blocks until a call may be made, or returns the context's error if it's cancelled first
+func (limiter *rateLimiter) wait(ctx context.Context) error {
+	if limiter.rate <= 0 {
+		return nil
+	}
+
+	for {
+		limiter.mutex.Lock()
+
+		now := time.Now()
+		limiter.tokens += now.Sub(limiter.last).Seconds() * limiter.rate
+		limiter.last = now
+
+		if limiter.tokens > limiter.capacity {
+			limiter.tokens = limiter.capacity
+		}
+
+		if limiter.tokens >= 1 {
+			limiter.tokens--
+			limiter.mutex.Unlock()
+
+			return nil
+		}
+
+		delay := time.Duration((1 - limiter.tokens) / limiter.rate * float64(time.Second))
+		limiter.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// withRateLimit returns a send handler that waits on the limiter before every attempt of a request, including retries
+func withRateLimit(limiter *rateLimiter) func(*aws.Request) {
+	return func(r *aws.Request) {
+		if r.Error != nil {
+			return
+		}
+
+		if err := limiter.wait(r.Context()); err != nil {
+			r.Error = err
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package cfn
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterAllowsFractionalRates(t *testing.T) {
+	limiter := newRateLimiter(0.5)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.wait(ctx); err != nil {
+		t.Fatalf("expected the first call to be allowed at once, got %s", err)
+	}
+
+	if err := limiter.wait(ctx); err == nil {
+		t.Errorf("expected a second call within two seconds to wait at half a call per second")
+	}
+
+	// two seconds later a whole call has accrued
+	limiter.mutex.Lock()
+	limiter.last = limiter.last.Add(-2 * time.Second)
+	limiter.mutex.Unlock()
+
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	if err := limiter.wait(ctx); err != nil {
+		t.Errorf("expected a call to be allowed once one accrued, got %s", err)
+	}
+}
+
+func TestRateLimiterHoldsOneSecondOfCalls(t *testing.T) {
+	limiter := newRateLimiter(3)
+
+	// idle time doesn't accrue more than a second of calls
+	limiter.last = limiter.last.Add(-time.Minute)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	for i := 0; i < 3; i++ {
+		if err := limiter.wait(ctx); err != nil {
+			t.Fatalf("expected call %d to be allowed at once, got %s", i+1, err)
+		}
+	}
+
+	if err := limiter.wait(ctx); err == nil {
+		t.Errorf("expected a fourth call to wait")
+	}
+}
@@ -0,0 +1,38 @@
+package cfn
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+)
+
+// DefaultMaxRetries is how many times a throttled or otherwise retryable AWS call is retried, unless configured otherwise
+const DefaultMaxRetries int = 5
+
+var (
+	maxRetries = DefaultMaxRetries
+
+	// maxRetryBackoff caps the delay between attempts
+	maxRetryBackoff = 20 * time.Second
+)
+
+// SetMaxRetries sets how many times a throttled or otherwise retryable AWS call is retried. Zero or less disables retries
+func SetMaxRetries(retries int) {
+	if retries < 0 {
+		retries = 0
+	}
+
+	maxRetries = retries
+	cfnClient = nil
+}
+
+// newRetryer retries throttling errors such as Throttling and ThrottlingException, and transient server errors, with
+// exponential backoff and full jitter. The SDK sleeps between attempts on the request context, so the timeout still
+// cancels a call that is backing off
+func newRetryer() aws.Retryer {
+	return retry.NewStandard(func(options *retry.StandardOptions) {
+		options.MaxAttempts = maxRetries + 1
+		options.MaxBackoff = maxRetryBackoff
+	})
+}
@@ -0,0 +1,86 @@
+package cfn
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+// useRetries retries throttled calls up to retries times with backoff short enough for tests, until the test ends
+func useRetries(t *testing.T, retries int) {
+	backoff := maxRetryBackoff
+
+	SetMaxRetries(retries)
+	maxRetryBackoff = time.Millisecond
+
+	t.Cleanup(func() {
+		SetMaxRetries(DefaultMaxRetries)
+		maxRetryBackoff = backoff
+	})
+}
+
+// throttleFirst answers the first throttled calls with a Throttling error and the rest with the test stack, counting every call
+func throttleFirst(t *testing.T, throttled int, attempts *int) {
+	useTestServer(t, func(w http.ResponseWriter, query url.Values) {
+		*attempts++
+
+		if *attempts <= throttled {
+			writeError(w, http.StatusBadRequest, "Throttling", "Rate exceeded")
+			return
+		}
+
+		writeResult(w, "DescribeStacks", "<Stacks><member><StackName>"+testStackName+"</StackName><StackId>"+testStackID+"</StackId>"+
+			"<StackStatus>CREATE_COMPLETE</StackStatus><CreationTime>2020-03-01T10:00:00Z</CreationTime></member></Stacks>")
+	})
+}
+
+func TestThrottledCallsAreRetried(t *testing.T) {
+	useRetries(t, 3)
+
+	attempts := 0
+	throttleFirst(t, 3, &attempts)
+
+	stack, err := RefreshStack(testStackName)
+	if err != nil {
+		t.Fatalf("expected the call to succeed once no longer throttled, got %s", err)
+	}
+
+	if *stack.Stacks[0].StackId != testStackID {
+		t.Errorf("expected the test stack, got %v", stack.Stacks)
+	}
+
+	if attempts != 4 {
+		t.Errorf("expected 3 throttled attempts and 1 successful one, got %d attempts", attempts)
+	}
+}
+
+func TestThrottledCallsGiveUpAfterMaxRetries(t *testing.T) {
+	useRetries(t, 2)
+
+	attempts := 0
+	throttleFirst(t, 3, &attempts)
+
+	if _, err := RefreshStack(testStackName); errorCode(err) != "Throttling" {
+		t.Errorf("expected the throttling error once retries ran out, got %v", err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected 1 attempt and 2 retries, got %d attempts", attempts)
+	}
+}
+
+func TestThrottledCallsAreNotRetriedWhenDisabled(t *testing.T) {
+	useRetries(t, 0)
+
+	attempts := 0
+	throttleFirst(t, 1, &attempts)
+
+	if _, err := RefreshStack(testStackName); err == nil {
+		t.Errorf("expected the throttling error without retries")
+	}
+
+	if attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts)
+	}
+}
@@ -0,0 +1,61 @@
+package cfn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/blueseph/cirrus/colors"
+)
+
+// TimeoutExitCode is the exit code when an operation runs out of time, so CI can tell a timeout from a failed operation
+const TimeoutExitCode = 124
+
+// operationContext bounds every CloudFormation call, paginator and waiter. It never expires unless a timeout is set
+var operationContext = context.Background()
+
+// cancelOperation releases the timer of the operation context
+var cancelOperation context.CancelFunc = func() {}
+
+// SetTimeout bounds the whole run of cirrus to the duration, counted from now. Zero or less removes the bound
+func SetTimeout(timeout time.Duration) {
+	cancelOperation()
+
+	if timeout <= 0 {
+		operationContext, cancelOperation = context.Background(), func() {}
+		return
+	}
+
+	operationContext, cancelOperation = context.WithTimeout(context.Background(), timeout)
+}
+
+// Context returns the context CloudFormation calls are made with, for callers paging through results themselves
+func Context() context.Context {
+	return operationContext
+}
+
+// requestCanceledCode is the error code of a call the SDK cut short because its context ended
+const requestCanceledCode string = "RequestCanceled"
+
+// IsTimeout determines if an error came from the timeout elapsing, rather than failing for another reason once it elapsed.
+// The only context calls are cancelled by is the timeout, so a call the SDK reports as cancelled timed out too
+func IsTimeout(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errorCode(err) == requestCanceledCode
+}
+
+// TimeoutError is returned when the timeout elapses while an operation is still in progress, with the resources still pending
+type TimeoutError struct {
+	Pending []string
+}
+
+func (err *TimeoutError) Error() string {
+	message := "Timed out waiting for the operation to finish"
+
+	if len(err.Pending) > 0 {
+		message += ". Resources still in progress: " + strings.Join(err.Pending, ", ")
+	}
+
+	return colors.Error(fmt.Sprintf("%s. The operation continues in CloudFormation", message))
+}
@@ -0,0 +1,58 @@
+package cfn
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/awserr"
+)
+
+func TestIsTimeout(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected bool
+	}{
+		{name: "no error", err: nil, expected: false},
+		{name: "deadline exceeded", err: context.DeadlineExceeded, expected: true},
+		{name: "wrapped deadline exceeded", err: fmt.Errorf("waiting: %w", context.DeadlineExceeded), expected: true},
+		{name: "canceled request", err: &aws.RequestCanceledError{Err: context.DeadlineExceeded}, expected: true},
+		{name: "canceled request code", err: awserr.New(requestCanceledCode, "request context canceled", nil), expected: true},
+		{name: "validation error", err: awserr.New("ValidationError", "Stack with id cirrus-test does not exist", nil), expected: false},
+		{name: "plain error", err: errors.New("template is invalid"), expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := IsTimeout(test.err); got != test.expected {
+				t.Errorf("expected IsTimeout(%v) to be %t", test.err, test.expected)
+			}
+		})
+	}
+}
+
+func TestIsTimeoutAfterTheTimeoutElapses(t *testing.T) {
+	useTestServer(t, func(w http.ResponseWriter, query url.Values) {
+		time.Sleep(200 * time.Millisecond)
+		writeError(w, http.StatusBadRequest, "ValidationError", "Stack with id cirrus-test does not exist")
+	})
+
+	SetTimeout(50 * time.Millisecond)
+	defer SetTimeout(0)
+
+	_, err := RefreshStack(testStackName)
+	if !IsTimeout(err) {
+		t.Errorf("expected a call cut short by the timeout to be a timeout, got %v", err)
+	}
+
+	// an unrelated failure isn't a timeout just because the timeout has since elapsed
+	if IsTimeout(errors.New("template is invalid")) {
+		t.Errorf("expected an unrelated error not to be a timeout once the timeout elapsed")
+	}
+}
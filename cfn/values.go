@@ -0,0 +1,101 @@
+package cfn
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/data"
+)
+
+// propertyValuesResult is the part of a DescribeChangeSet response holding the property values returned with
+// IncludePropertyValues, which the SDK predates and drops
+type propertyValuesResult struct {
+	Changes []struct {
+		ResourceChange struct {
+			LogicalResourceID string `xml:"LogicalResourceId"`
+			Details           []struct {
+				Target struct {
+					Attribute   string
+					Name        string
+					Path        string
+					BeforeValue *string
+					AfterValue  *string
+				}
+			} `xml:"Details>member"`
+		}
+	} `xml:"DescribeChangeSetResult>Changes>member"`
+	NextToken *string `xml:"DescribeChangeSetResult>NextToken"`
+}
+
+// GetPropertyValues describes the change set with IncludePropertyValues, returning the before and after values of the changed
+// properties of each resource by logical ID, in the order of the change details. Every page of changes is read. An error means
+// the values are unavailable, whether the API or the caller's permissions don't allow them
+func GetPropertyValues(info data.StackInfo) (map[string][]data.PropertyValue, error) {
+	input := cloudformation.DescribeChangeSetInput{
+		StackName:     &info.StackName,
+		ChangeSetName: &info.ChangeSetName,
+	}
+
+	client := getClient()
+	values := make(map[string][]data.PropertyValue)
+
+	for {
+		req := client.DescribeChangeSetRequest(&input)
+		req.Handlers.Build.PushBack(withQueryParameter("IncludePropertyValues", "true"))
+
+		var body []byte
+		req.Handlers.Unmarshal.PushFront(captureResponseBody(&body))
+
+		if _, err := req.Send(operationContext); err != nil {
+			return nil, err
+		}
+
+		var result propertyValuesResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, err
+		}
+
+		for _, change := range result.Changes {
+			logicalID := change.ResourceChange.LogicalResourceID
+
+			for _, detail := range change.ResourceChange.Details {
+				values[logicalID] = append(values[logicalID], data.PropertyValue{
+					Attribute: detail.Target.Attribute,
+					Name:      detail.Target.Name,
+					Path:      detail.Target.Path,
+					Before:    detail.Target.BeforeValue,
+					After:     detail.Target.AfterValue,
+				})
+			}
+		}
+
+		if result.NextToken == nil || *result.NextToken == "" {
+			return values, nil
+		}
+
+		input.NextToken = result.NextToken
+	}
+}
+
+// captureResponseBody returns an unmarshal handler that copies the raw response body, leaving it for the SDK to unmarshal too
+func captureResponseBody(body *[]byte) func(*aws.Request) {
+	return func(r *aws.Request) {
+		if r.Error != nil || r.HTTPResponse == nil || r.HTTPResponse.Body == nil {
+			return
+		}
+
+		captured, err := ioutil.ReadAll(r.HTTPResponse.Body)
+		r.HTTPResponse.Body.Close()
+
+		if err != nil {
+			r.Error = err
+			return
+		}
+
+		*body = captured
+		r.HTTPResponse.Body = ioutil.NopCloser(bytes.NewReader(captured))
+	}
+}
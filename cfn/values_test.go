@@ -0,0 +1,60 @@
+package cfn
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/blueseph/cirrus/data"
+)
+
+// propertyChange renders a resource change of a change set with a single detail changing its property from before to after
+func propertyChange(logicalID string, name string, before string, after string) string {
+	return "<member><Type>Resource</Type><ResourceChange><Action>Modify</Action><LogicalResourceId>" + logicalID + "</LogicalResourceId>" +
+		"<Details><member><Target><Attribute>Properties</Attribute><Name>" + name + "</Name><Path>/Properties/" + name + "</Path>" +
+		"<BeforeValue>" + before + "</BeforeValue><AfterValue>" + after + "</AfterValue></Target></member></Details></ResourceChange></member>"
+}
+
+func TestGetPropertyValuesAcrossPages(t *testing.T) {
+	pages := map[string]string{
+		"":       "<Changes>" + propertyChange("Bucket", "BucketName", "old-logs", "new-logs") + "</Changes><NextToken>second</NextToken>",
+		"second": "<Changes>" + propertyChange("Queue", "DelaySeconds", "0", "5") + "</Changes>",
+	}
+
+	fetched := make([]string, 0)
+
+	useTestServer(t, func(w http.ResponseWriter, query url.Values) {
+		if query.Get("IncludePropertyValues") != "true" {
+			t.Errorf("expected every page to include property values, got %v", query)
+		}
+
+		token := query.Get("NextToken")
+		fetched = append(fetched, token)
+
+		page, ok := pages[token]
+		if !ok {
+			t.Fatalf("unexpected page %q", token)
+		}
+
+		writeResult(w, "DescribeChangeSet", "<ChangeSetName>cirrus-test</ChangeSetName><Status>CREATE_COMPLETE</Status>"+page)
+	})
+
+	values, err := GetPropertyValues(data.StackInfo{StackName: testStackName, ChangeSetName: "cirrus-test"})
+	if err != nil {
+		t.Fatalf("unable to get the property values: %s", err)
+	}
+
+	if len(fetched) != 2 {
+		t.Errorf("expected both pages to be fetched, fetched %q", fetched)
+	}
+
+	queue := values["Queue"]
+	if len(queue) != 1 || *queue[0].Before != "0" || *queue[0].After != "5" {
+		t.Errorf("expected the values of the resource on the second page, got %v", values)
+	}
+
+	bucket := values["Bucket"]
+	if len(bucket) != 1 || bucket[0].Name != "BucketName" || *bucket[0].After != "new-logs" {
+		t.Errorf("expected the values of the resource on the first page, got %v", values)
+	}
+}
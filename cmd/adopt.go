@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/ui"
+	"github.com/urfave/cli/v2"
+)
+
+var adoptFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:     "change-set",
+		Aliases:  []string{"c"},
+		Usage:    "Specifies the ID (ARN) of the change set to execute",
+		Required: true,
+	},
+	&cli.StringFlag{
+		Name:     "stack",
+		Aliases:  []string{"s"},
+		Usage:    "Specifies the stack name or stack ID the change set must belong to",
+		Required: true,
+	},
+	&cli.BoolFlag{
+		Name:    "yes",
+		Aliases: []string{"y"},
+		Usage:   "Executes the change set without asking, in table and lines output",
+	},
+	regionFlag,
+	profileFlag,
+}
+
+// AdoptCommand returns the CLI construct that executes a change set created by another tool and watches events
+var AdoptCommand = &cli.Command{
+	Name:   "adopt",
+	Usage:  "Preview and execute an existing change set and watch stack events",
+	Before: applyConfigDefaults,
+	Action: adoptAction,
+	Flags:  append(append(adoptFlags, displayFlags...), hookFlags...),
+}
+
+func adoptAction(c *cli.Context) error {
+	cfn.SetRegion(c.String("region"))
+	cfn.SetProfile(c.String("profile"))
+	routeNotices(c)
+
+	options, err := displayOptions(c)
+	if err != nil {
+		return err
+	}
+
+	options.AutoApprove = c.Bool("yes")
+
+	result, err := Adopt(c.String("stack"), c.String("change-set"), options)
+
+	return finishAction(c, result, err)
+}
+
+// Adopt previews an externally created change set, then executes and watches it on confirmation
+func Adopt(stackName string, changeSetID string, options ui.Options) (data.DeployResult, error) {
+	err := cfn.VerifyAWSCredentials()
+	if err != nil {
+		return data.DeployResult{}, err
+	}
+
+	changeSet, err := cfn.DescribeChangeSetByID(changeSetID)
+	if err != nil {
+		return data.DeployResult{}, err
+	}
+
+	err = verifyAdoptable(stackName, changeSet.DescribeChangeSetOutput)
+	if err != nil {
+		return data.DeployResult{}, err
+	}
+
+	info := data.StackInfo{
+		StackName:     *changeSet.StackName,
+		StackID:       *changeSet.StackId,
+		ChangeSetName: *changeSet.ChangeSetId,
+	}
+
+	// a change set for a new stack leaves it in REVIEW_IN_PROGRESS, which doesn't count as existing
+	exists, err := cfn.DetermineIfStackExists(info.StackID)
+	if err != nil {
+		return data.DeployResult{}, err
+	}
+
+	operation := cfn.StackOperationCreate
+	if exists {
+		operation = cfn.StackOperationUpdate
+	}
+
+	result, err := ui.DisplayChanges(info, changeSet, operation, options)
+	if err != nil || !result.Executed {
+		return result, err
+	}
+
+	return withStackDetails(result)
+}
+
+func verifyAdoptable(stackName string, changeSet *cloudformation.DescribeChangeSetOutput) error {
+	if stackName != *changeSet.StackName && stackName != *changeSet.StackId {
+		return errors.New(colors.Error(fmt.Sprintf("Change set %s belongs to stack %s, not %s", *changeSet.ChangeSetName, *changeSet.StackName, stackName)))
+	}
+
+	return cfn.VerifyExecutable(changeSet)
+}
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/blueseph/cirrus/colors"
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// projectConfigFile is the config file read from the working directory, over the one in the home directory
+const projectConfigFile = ".cirrus.yaml"
+
+// flagConfig holds default flag values, with sections for individual stacks keyed by stack name
+type flagConfig struct {
+	Defaults map[string]interface{}            `yaml:"defaults"`
+	Stacks   map[string]map[string]interface{} `yaml:"stacks"`
+}
+
+// configLocations returns the config files to read, lowest precedence first
+func configLocations() []string {
+	locations := make([]string, 0)
+
+	if home, err := os.UserHomeDir(); err == nil {
+		locations = append(locations, filepath.Join(home, ".cirrus", "config.yaml"))
+	}
+
+	return append(locations, projectConfigFile)
+}
+
+// applyConfigDefaults fills in flags that weren't given on the command line or by environment variable from the config files.
+// Precedence is the command line, then the environment, then the section of the stack, then the config defaults, with
+// .cirrus.yaml over ~/.cirrus/config.yaml. Keys for flags the command doesn't have are ignored, so one file serves every command
+func applyConfigDefaults(c *cli.Context) error {
+	values := make(map[string]interface{})
+
+	for _, location := range configLocations() {
+		config, err := readFlagConfig(location)
+		if err != nil {
+			return err
+		}
+
+		for name, value := range config.Defaults {
+			values[name] = value
+		}
+	}
+
+	// stack sections apply after every file's defaults, so a stack setting from either file beats a general default
+	for _, location := range configLocations() {
+		config, err := readFlagConfig(location)
+		if err != nil {
+			return err
+		}
+
+		for name, value := range config.Stacks[c.String("stack")] {
+			values[name] = value
+		}
+	}
+
+	for _, flag := range c.Command.Flags {
+		name := flag.Names()[0]
+
+		value, ok := values[name]
+		if !ok || c.IsSet(name) {
+			continue
+		}
+
+		if err := setConfigValue(c, name, value); err != nil {
+			return errors.New(colors.Error(fmt.Sprintf("Invalid config value for %s: %s", name, err.Error())))
+		}
+	}
+
+	return nil
+}
+
+func readFlagConfig(location string) (flagConfig, error) {
+	var config flagConfig
+
+	contents, err := ioutil.ReadFile(location)
+	if os.IsNotExist(err) {
+		return config, nil
+	}
+
+	if err != nil {
+		return config, err
+	}
+
+	if err := yaml.Unmarshal(contents, &config); err != nil {
+		return config, errors.New(colors.Error(fmt.Sprintf("Unable to load config %s. Config must be valid YAML with defaults and stacks sections", location)))
+	}
+
+	return config, nil
+}
+
+// setConfigValue sets a flag from a config value, setting each item of a list for flags that repeat
+func setConfigValue(c *cli.Context, name string, value interface{}) error {
+	items, ok := value.([]interface{})
+	if !ok {
+		items = []interface{}{value}
+	}
+
+	for _, item := range items {
+		if err := c.Set(name, fmt.Sprint(item)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/urfave/cli/v2"
+)
+
+var discoverFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "template",
+		Aliases: []string{"t"},
+		Value:   "./template.yaml",
+		Usage:   "Specifies location of template `file`",
+	},
+	&cli.StringFlag{
+		Name:     "stack",
+		Aliases:  []string{"s"},
+		Usage:    "Specifies the stack the resources would be imported into. Resources it already manages are skipped",
+		Required: true,
+	},
+	&cli.StringFlag{
+		Name:  "output-file",
+		Value: "./resources-to-import.json",
+		Usage: "Writes the skeleton resources-to-import `file` here",
+	},
+	regionFlag,
+	profileFlag,
+}
+
+// DiscoverImportsCommand returns the CLI construct that finds template resources which already exist and could be imported
+var DiscoverImportsCommand = &cli.Command{
+	Name:   "discover-imports",
+	Usage:  "Find template resources that already exist outside the stack and write a resources-to-import skeleton",
+	Before: applyConfigDefaults,
+	Action: discoverImportsAction,
+	Flags:  discoverFlags,
+}
+
+func discoverImportsAction(c *cli.Context) error {
+	cfn.SetRegion(c.String("region"))
+	cfn.SetProfile(c.String("profile"))
+
+	template, err := ioutil.ReadFile(c.String("template"))
+	if err != nil {
+		return err
+	}
+
+	err = DiscoverImports(template, c.String("stack"), c.String("output-file"))
+	if err != nil {
+		fmt.Println(colors.Error("Cirrus encountered a fatal error:"))
+		return err
+	}
+
+	return nil
+}
+
+// DiscoverImports describes each supported template resource outside the stack, reports which already exist, and writes
+// them to a resources-to-import skeleton. Discovery is best-effort: only the types in data.ImportIdentifierProperties
+// named with a literal string can be checked
+func DiscoverImports(template []byte, stackName string, location string) error {
+	parsed, err := data.ParseTemplate(template)
+	if err != nil {
+		return err
+	}
+
+	err = cfn.VerifyAWSCredentials()
+	if err != nil {
+		return err
+	}
+
+	managed, err := managedResources(stackName)
+	if err != nil {
+		return err
+	}
+
+	candidates, unchecked := data.FindImportCandidates(parsed, managed)
+	found := make([]data.ImportCandidate, 0)
+
+	for _, candidate := range candidates {
+		exists, err := cfn.ResourceExists(candidate)
+		if err != nil {
+			fmt.Println(colors.Error(fmt.Sprintf("%s - unable to check %s: %s", candidate.LogicalResourceID, candidate.Identifier, err)))
+			continue
+		}
+
+		if exists {
+			found = append(found, candidate)
+			fmt.Printf("%s %s %s exists\n", colors.Green("✓"), colors.Teal(candidate.LogicalResourceID), candidate.Identifier)
+		} else {
+			fmt.Printf("  %s %s not found\n", colors.Teal(candidate.LogicalResourceID), candidate.Identifier)
+		}
+	}
+
+	for _, logicalID := range unchecked {
+		fmt.Printf("%s %s can't be checked. Its type isn't supported or it has no literal name\n", colors.Warning("?"), colors.Teal(logicalID))
+	}
+
+	if len(found) == 0 {
+		fmt.Println(colors.Status("No existing resources found to import"))
+		return nil
+	}
+
+	skeleton, err := json.MarshalIndent(data.ResourcesToImport(found), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = ioutil.WriteFile(location, skeleton, 0644)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(colors.Status(fmt.Sprintf("Wrote %d resource(s) to import to %s", len(found), location)))
+
+	return nil
+}
+
+// managedResources lists the logical IDs of the resources a stack already manages, none if the stack doesn't exist
+func managedResources(stackName string) (map[string]bool, error) {
+	managed := make(map[string]bool)
+
+	exists, err := cfn.DetermineIfStackExists(stackName)
+	if err != nil || !exists {
+		return managed, err
+	}
+
+	paginator := cfn.GetStackResources(data.StackInfo{StackName: stackName})
+
+	for _, resource := range data.GetResourcesFromPaginator(cfn.Context(), &paginator) {
+		managed[*resource.LogicalResourceId] = true
+	}
+
+	return managed, nil
+}
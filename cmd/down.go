@@ -3,7 +3,9 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"strings"
 
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/blueseph/cirrus/cfn"
 	"github.com/blueseph/cirrus/colors"
 	"github.com/blueseph/cirrus/data"
@@ -15,66 +17,187 @@ var downFlags = []cli.Flag{
 	&cli.StringFlag{
 		Name:     "stack",
 		Aliases:  []string{"s"},
-		Usage:    "Specifies stack name",
+		Usage:    "Specifies stack name or stack ID",
 		Required: true,
 	},
+	&cli.BoolFlag{
+		Name:  "force-delete",
+		Usage: "Deletes a stack stuck in DELETE_FAILED. Resources that fail to delete are abandoned and must be cleaned up by hand",
+	},
+	&cli.BoolFlag{
+		Name:  "auto-retain-on-failure",
+		Usage: "When the deletion fails, retries it once, retaining the resources that failed to delete",
+	},
+	&cli.BoolFlag{
+		Name:  "require-exists",
+		Usage: "Fails when the stack doesn't exist instead of treating it as already deleted",
+	},
+	&cli.BoolFlag{
+		Name:  "resources",
+		Usage: "Prints the resources the deletion would remove, in estimated deletion order with retained resources marked, without deleting",
+	},
+	&cli.BoolFlag{
+		Name:    "yes",
+		Aliases: []string{"y"},
+		Usage:   "Deletes without asking to type the stack name, in table and lines output, e.g. in CI",
+	},
+	regionFlag,
+	profileFlag,
 }
 
+// maxRetainRetries bounds how many times a failed deletion is retried with its failed resources retained
+const maxRetainRetries = 1
+
 // DownCommand returns the CLI construct that destroys a CloudFormation stack and watches events
 var DownCommand = &cli.Command{
 	Name:   "down",
 	Usage:  "Bring down a CloudFormation template and watch stack events",
+	Before: applyConfigDefaults,
 	Action: downAction,
-	Flags:  downFlags,
+	Flags:  append(append(downFlags, displayFlags...), hookFlags...),
 }
 
 func downAction(c *cli.Context) error {
-	err := Down(c.String("stack"))
+	cfn.SetRegion(c.String("region"))
+	cfn.SetProfile(c.String("profile"))
+	routeNotices(c)
+
+	options, err := displayOptions(c)
 	if err != nil {
-		fmt.Println(colors.Error("Cirrus encountered a fatal error:"))
 		return err
 	}
 
-	return nil
+	if c.Bool("resources") {
+		err = DownPreview(c.String("stack"), options)
+		if err != nil {
+			colors.Notice(colors.Error("Cirrus encountered a fatal error:"))
+			return err
+		}
+
+		return nil
+	}
+
+	options.Delete.ForceDelete = c.Bool("force-delete")
+	options.AutoApprove = c.Bool("yes")
+
+	result, err := Down(c.String("stack"), c.Bool("auto-retain-on-failure"), c.Bool("require-exists"), options)
+
+	return finishAction(c, result, err)
 }
 
-// Down manages the stack deletion lifecycle
-func Down(stackName string) error {
+// Down manages the stack deletion lifecycle, returning the structured result of the deletion. With retainOnFailure, a failed
+// deletion is retried with the resources that failed to delete retained. A missing stack is already deleted unless requireExists
+func Down(stackName string, retainOnFailure bool, requireExists bool, options ui.Options) (data.DeployResult, error) {
 	err := cfn.VerifyAWSCredentials()
 	if err != nil {
-		return err
+		return data.DeployResult{}, err
 	}
 
 	exists, err := cfn.DetermineIfStackExists(stackName)
 	if err != nil {
-		return err
+		return data.DeployResult{}, err
 	}
 
 	if !exists {
-		return errors.New(colors.Error(fmt.Sprintf("Could not find stack %s", stackName)))
+		return data.DeployResult{}, handleMissingStack(stackName, requireExists)
 	}
 
+	// capture the stack ID before deleting, since events can't be polled by name once the deletion completes
 	stack, err := cfn.GetStack(stackName)
+	if cfn.IsStackNotFound(err) {
+		return data.DeployResult{}, handleMissingStack(stackName, requireExists)
+	}
+
 	if err != nil {
-		return err
+		return data.DeployResult{}, err
 	}
 
+	// a stack ID pins the deletion to one stack generation; resolve it back to a name for display
 	info := data.StackInfo{
-		StackName: stackName,
+		StackName: *stack.DescribeStacksOutput.Stacks[0].StackName,
 		StackID:   *stack.DescribeStacksOutput.Stacks[0].StackId,
 	}
 
+	if options.Delete.ForceDelete {
+		colors.Notice(colors.Status("Force deleting. Resources that fail to delete will be left behind in your account, outside of any stack"))
+	}
+
+	// the deletion hints are best effort, so a template that can't be read leaves the preview without them
+	template, _ := cfn.GetDeployedTemplate(info)
+
 	paginator := cfn.GetStackResources(info)
+
+	resources := data.GetResourcesFromPaginator(cfn.Context(), &paginator)
+
+	result, err := ui.DisplayDeletes(info, resources, template, options)
+
+	for retries := 0; retainOnFailure && retries < maxRetainRetries && result.Status == cloudformation.StackStatusDeleteFailed; retries++ {
+		retained := data.DeleteFailedResourceIDs(result.Rows)
+		if len(retained) == 0 {
+			break
+		}
+
+		colors.Notice(colors.Status("Retrying the deletion, retaining " + strings.Join(retained, ", ")))
+
+		// the deletion was already approved, so the retry doesn't ask again where the output allows it
+		options.AutoApprove = true
+		options.Delete.RetainResources = retained
+
+		paginator = cfn.GetStackResources(info)
+		resources = data.GetResourcesFromPaginator(cfn.Context(), &paginator)
+
+		result, err = ui.DisplayDeletes(info, resources, template, options)
+		if err == nil {
+			colors.Notice(colors.Status(fmt.Sprintf("%s is %s. These resources were retained and remain in your account outside of any stack: %s",
+				info.StackName, result.Status, strings.Join(retained, ", "))))
+		}
+	}
+
+	return result, err
+}
+
+// DownPreview prints the resources deleting the stack would remove, in their estimated deletion order, marking the resources
+// whose DeletionPolicy retains them. Nothing is deleted
+func DownPreview(stackName string, options ui.Options) error {
+	err := cfn.VerifyAWSCredentials()
 	if err != nil {
 		return err
 	}
 
-	resources := data.GetResourcesFromPaginator(&paginator)
+	stack, err := cfn.GetStack(stackName)
+	if err != nil {
+		return err
+	}
 
-	err = ui.DisplayDeletes(info, resources)
+	info := data.StackInfo{
+		StackName: *stack.Stacks[0].StackName,
+		StackID:   *stack.Stacks[0].StackId,
+	}
+
+	template, err := cfn.GetDeployedTemplate(info)
 	if err != nil {
 		return err
 	}
 
+	paginator := cfn.GetStackResources(info)
+
+	ui.PrintDeletes(info, data.GetResourcesFromPaginator(cfn.Context(), &paginator), template, options)
+
+	return nil
+}
+
+// handleMissingStack reports a stack that doesn't exist, which is an error only when requireExists
+func handleMissingStack(stackName string, requireExists bool) error {
+	message := fmt.Sprintf("Stack %s does not exist; nothing to delete", stackName)
+	if data.IsStackID(stackName) {
+		message = fmt.Sprintf("No active stack with ID %s exists; nothing to delete", stackName)
+	}
+
+	if requireExists {
+		return errors.New(colors.Error(message))
+	}
+
+	colors.Notice(colors.Status(message))
+
 	return nil
 }
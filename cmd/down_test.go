@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHandleMissingStack(t *testing.T) {
+	if err := handleMissingStack("cirrus-test", false); err != nil {
+		t.Errorf("expected a missing stack to be already deleted, got %s", err)
+	}
+
+	err := handleMissingStack("cirrus-test", true)
+	if err == nil {
+		t.Fatalf("expected a missing stack to fail with --require-exists")
+	}
+
+	if !strings.Contains(err.Error(), "Stack cirrus-test does not exist; nothing to delete") {
+		t.Errorf("expected a friendly message, got %s", err)
+	}
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/utils"
+)
+
+const defaultEditor string = "vi"
+
+// editParameters opens the parameters as JSON in $EDITOR and reads them back once the editor exits, re-opening the editor
+// until the JSON is valid or the user gives up
+func editParameters(parameters []cloudformation.Parameter, coerce bool) ([]cloudformation.Parameter, error) {
+	contents, err := data.MarshalParameters(parameters)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := ioutil.TempFile("", "cirrus-parameters-*.json")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(contents)
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		err = runEditor(file.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		edited, err := data.GetParameters(file.Name(), coerce)
+		if err == nil {
+			return edited, nil
+		}
+
+		fmt.Println(err)
+
+		retry, askErr := utils.AskYesNoQuestion(colors.Status("Edit the parameters again? [Y/N]"))
+		if askErr != nil || !retry {
+			return nil, err
+		}
+	}
+}
+
+// runEditor opens a file in $EDITOR, which may include arguments, and waits for it to exit
+func runEditor(path string) error {
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{defaultEditor}
+	}
+
+	command := exec.Command(editor[0], append(editor[1:], path)...)
+	command.Stdin = os.Stdin
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	return command.Run()
+}
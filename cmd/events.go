@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/ui"
+	"github.com/blueseph/cirrus/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var eventsFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:     "stack",
+		Aliases:  []string{"s"},
+		Usage:    "Specifies stack name or stack ID",
+		Required: true,
+	},
+	&cli.BoolFlag{
+		Name:  "from-beginning",
+		Usage: "Replays the events of the current operation from its start before streaming new ones. Bounded by --max-stack-events",
+	},
+	maxStackEventsFlag,
+	waitStatesFlag,
+	shortTypesFlag,
+	regionFlag,
+	profileFlag,
+}
+
+// EventsCommand returns the CLI construct that attaches to a stack operation already in progress and watches its events
+var EventsCommand = &cli.Command{
+	Name:   "events",
+	Usage:  "Watch the events of a stack operation that is already in progress",
+	Before: applyConfigDefaults,
+	Action: eventsAction,
+	Flags:  append(eventsFlags, hookFlags...),
+}
+
+func eventsAction(c *cli.Context) error {
+	cfn.SetRegion(c.String("region"))
+	cfn.SetProfile(c.String("profile"))
+
+	waitStates, err := data.ParseStackStatuses(utils.SplitList(c.String("stack-status-wait-states")))
+	if err != nil {
+		return err
+	}
+
+	options := ui.Options{
+		Output:         ui.OutputLines,
+		MaxStackEvents: c.Int("max-stack-events"),
+		ShortTypes:     c.Bool("short-types"),
+		WaitStates:     waitStates,
+	}
+
+	result, err := Events(c.String("stack"), c.Bool("from-beginning"), options)
+
+	return finishAction(c, result, err)
+}
+
+// Events watches the operation in progress on a stack until it finishes, optionally replaying the events it produced so far
+func Events(stackName string, fromBeginning bool, options ui.Options) (data.DeployResult, error) {
+	err := cfn.VerifyAWSCredentials()
+	if err != nil {
+		return data.DeployResult{}, err
+	}
+
+	stack, err := cfn.GetStack(stackName)
+	if err != nil {
+		return data.DeployResult{}, err
+	}
+
+	info := data.StackInfo{
+		StackName: *stack.Stacks[0].StackName,
+		StackID:   *stack.Stacks[0].StackId,
+	}
+
+	status := stack.Stacks[0].StackStatus
+	if !utils.ContainsStackStatus(data.PendingStackStatus, cloudformation.ResourceStatus(status)) {
+		fmt.Println(colors.Status(fmt.Sprintf("%s is not being changed, its status is %s", info.StackName, status)))
+		return data.DeployResult{}, nil
+	}
+
+	// without permission to describe events, the watch follows only the stack status
+	since, err := cfn.GetLatestStackEventTime(info)
+	if err != nil && !cfn.IsAccessDenied(err) {
+		return data.DeployResult{}, err
+	}
+
+	if fromBeginning {
+		since, err = operationCutoff(info, since, options.MaxStackEvents)
+		if err != nil {
+			return data.DeployResult{}, err
+		}
+	}
+
+	return ui.WatchStack(info, since, options)
+}
+
+// operationCutoff finds the cutoff that includes every event of the current operation, falling back to the oldest retained event
+// when the operation started more than the bounded number of events ago
+func operationCutoff(info data.StackInfo, latest time.Time, limit int) (time.Time, error) {
+	events, err := cfn.GetOperationEvents(info, limit)
+	if cfn.IsAccessDenied(err) {
+		return latest, nil
+	}
+
+	if err != nil || len(events) == 0 {
+		return latest, err
+	}
+
+	start, ok := data.OperationStartTime(events)
+	if !ok {
+		fmt.Println(colors.Status(fmt.Sprintf("The operation started more than %d events ago, replaying the most recent %d", limit, len(events))))
+		start = *events[len(events)-1].Timestamp
+	}
+
+	// the cutoff is exclusive, so step back to include the event the operation started with
+	return start.Add(-time.Nanosecond), nil
+}
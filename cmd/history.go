@@ -0,0 +1,121 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/urfave/cli/v2"
+)
+
+// cloudTrailRetentionDays is how far back CloudTrail's event history goes
+const cloudTrailRetentionDays int = 90
+
+var historyFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:     "stack",
+		Aliases:  []string{"s"},
+		Usage:    "Specifies stack name or stack ID. A deleted stack can be given by name",
+		Required: true,
+	},
+	&cli.IntFlag{
+		Name:  "days",
+		Usage: "Looks back `n` days, up to the 90 days CloudTrail keeps",
+		Value: 30,
+	},
+	&cli.IntFlag{
+		Name:  "limit",
+		Usage: "Shows at most `count` changes, most recent first",
+		Value: 20,
+	},
+	regionFlag,
+	profileFlag,
+}
+
+// HistoryCommand returns the CLI construct that shows who changed a stack and when, from CloudTrail
+var HistoryCommand = &cli.Command{
+	Name:   "history",
+	Usage:  "Show who changed a CloudFormation stack and when, from CloudTrail. Requires cloudtrail:LookupEvents",
+	Before: applyConfigDefaults,
+	Action: historyAction,
+	Flags:  historyFlags,
+}
+
+func historyAction(c *cli.Context) error {
+	cfn.SetRegion(c.String("region"))
+	cfn.SetProfile(c.String("profile"))
+
+	err := History(c.String("stack"), c.Int("days"), c.Int("limit"))
+	if err != nil {
+		fmt.Println(colors.Error("Cirrus encountered a fatal error:"))
+		return err
+	}
+
+	return nil
+}
+
+// History prints the changes made to a stack in the last days, with the principal that made each one, newest first
+func History(stackName string, days int, limit int) error {
+	if days < 1 || days > cloudTrailRetentionDays {
+		return errors.New(colors.Error(fmt.Sprintf("--days must be between 1 and %d, the days CloudTrail keeps", cloudTrailRetentionDays)))
+	}
+
+	if limit < 1 {
+		return errors.New(colors.Error("--limit must be at least 1"))
+	}
+
+	err := cfn.VerifyAWSCredentials()
+	if err != nil {
+		return err
+	}
+
+	info := data.StackInfo{StackName: stackName}
+
+	// a deleted stack can't be described, but its history can still be found by name
+	stack, err := cfn.GetStack(stackName)
+	if err != nil && !cfn.IsStackNotFound(err) {
+		return err
+	}
+
+	if err == nil {
+		info.StackName = *stack.Stacks[0].StackName
+		info.StackID = *stack.Stacks[0].StackId
+	}
+
+	entries, err := cfn.GetDeployHistory(info, time.Now().AddDate(0, 0, -days), limit)
+	if err != nil {
+		return err
+	}
+
+	if len(entries) == 0 {
+		fmt.Println(colors.Status(fmt.Sprintf("No changes to %s were recorded by CloudTrail in this region in the last %d days", info.StackName, days)))
+		return nil
+	}
+
+	operationWidth := 0
+	for _, entry := range entries {
+		if len(entry.Operation) > operationWidth {
+			operationWidth = len(entry.Operation)
+		}
+	}
+
+	for _, entry := range entries {
+		// pad before coloring, since the escape codes would throw off the widths
+		operation := colors.Teal(fmt.Sprintf("%-*s", operationWidth, entry.Operation))
+		if entry.ErrorCode != "" {
+			operation = colors.Red(fmt.Sprintf("%-*s", operationWidth, entry.Operation))
+		}
+
+		line := fmt.Sprintf("  %s %s %s", entry.Time.Local().Format(time.RFC3339), operation, entry.Principal)
+		if entry.ErrorCode != "" {
+			line += colors.Red(" (failed: " + entry.ErrorCode + ")")
+		}
+
+		fmt.Println(line)
+	}
+
+	return nil
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/ui"
+	"github.com/urfave/cli/v2"
+)
+
+var hookFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:  "on-success",
+		Usage: "Runs shell `command` once the operation succeeds. CIRRUS_STACK_NAME, CIRRUS_STACK_ID and CIRRUS_STACK_STATUS are set in its environment",
+	},
+	&cli.StringFlag{
+		Name:  "on-failure",
+		Usage: "Runs shell `command` once the operation fails. CIRRUS_STACK_NAME, CIRRUS_STACK_ID and CIRRUS_STACK_STATUS are set in its environment",
+	},
+	&cli.BoolFlag{
+		Name:  "fail-on-hook",
+		Usage: "Exits with an error when the --on-success or --on-failure command fails",
+	},
+}
+
+// runHook runs the --on-success or --on-failure command matching the outcome of the operation
+func runHook(c *cli.Context, result data.DeployResult, operationErr error) error {
+	command := c.String("on-success")
+	if operationErr != nil {
+		command = c.String("on-failure")
+	} else if !result.Executed {
+		return nil
+	}
+
+	if command == "" {
+		return nil
+	}
+
+	hook := exec.Command("sh", "-c", command)
+	hook.Stdout = os.Stdout
+	// json output keeps stdout for the rows alone
+	if ui.OutputFormat(c.String("output")) == ui.OutputJSON {
+		hook.Stdout = os.Stderr
+	}
+	hook.Stderr = os.Stderr
+	hook.Env = append(os.Environ(),
+		"CIRRUS_STACK_NAME="+result.StackName,
+		"CIRRUS_STACK_ID="+result.StackID,
+		"CIRRUS_STACK_STATUS="+string(result.Status),
+	)
+
+	err := hook.Run()
+	if err == nil {
+		return nil
+	}
+
+	if c.Bool("fail-on-hook") {
+		return errors.New(colors.Error(fmt.Sprintf("Hook command %q failed: %s", command, err)))
+	}
+
+	colors.Notice(colors.Status(fmt.Sprintf("Hook command %q failed: %s", command, err)))
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var listFlags = []cli.Flag{
+	&cli.BoolFlag{
+		Name:  "all",
+		Usage: "Includes deleted stacks, which CloudFormation lists for 90 days",
+	},
+	&cli.BoolFlag{
+		Name:  "stale-reviews",
+		Usage: "Shows only stacks stuck in REVIEW_IN_PROGRESS, created by a change set that was never executed",
+	},
+	regionFlag,
+	profileFlag,
+}
+
+// ListCommand returns the CLI construct that lists the stacks in the account and region
+var ListCommand = &cli.Command{
+	Name:   "list",
+	Usage:  "List CloudFormation stacks and flag ones left in REVIEW_IN_PROGRESS",
+	Before: applyConfigDefaults,
+	Action: listAction,
+	Flags:  listFlags,
+}
+
+func listAction(c *cli.Context) error {
+	cfn.SetRegion(c.String("region"))
+	cfn.SetProfile(c.String("profile"))
+
+	err := List(c.Bool("all"), c.Bool("stale-reviews"))
+	if err != nil {
+		fmt.Println(colors.Error("Cirrus encountered a fatal error:"))
+		return err
+	}
+
+	return nil
+}
+
+// stackStatusColor picks the tint of a stack status by whether it succeeded, failed or is still in progress
+func stackStatusColor(status cloudformation.StackStatus) func(...interface{}) string {
+	switch {
+	case utils.ContainsStackStatus(data.NegativeStackStatus, cloudformation.ResourceStatus(status)):
+		return colors.Red
+	case utils.ContainsStackStatus(data.PendingStackStatus, cloudformation.ResourceStatus(status)):
+		return colors.Yellow
+	case utils.ContainsStackStatus(data.PositiveStackStatus, cloudformation.ResourceStatus(status)):
+		return colors.Green
+	}
+
+	return colors.White
+}
+
+// List prints every active stack, or every stack including deleted ones, with its status colored by outcome and its creation
+// time. With staleReviewsOnly, only stacks stuck in REVIEW_IN_PROGRESS are printed, with how long they've been there
+func List(includeDeleted bool, staleReviewsOnly bool) error {
+	err := cfn.VerifyAWSCredentials()
+	if err != nil {
+		return err
+	}
+
+	summaries, err := cfn.ListStacks()
+	if err != nil {
+		return err
+	}
+
+	listings := data.ListStacks(summaries, includeDeleted)
+
+	nameWidth := 0
+	for _, listing := range listings {
+		if len(listing.StackName) > nameWidth {
+			nameWidth = len(listing.StackName)
+		}
+	}
+
+	staleReviews := 0
+
+	for _, listing := range listings {
+		if listing.IsStaleReview() {
+			staleReviews++
+
+			age := time.Since(listing.Updated).Round(time.Minute)
+			fmt.Printf("%s %s %s for %s\n", colors.Warning("!"), colors.Teal(listing.StackName), colors.Yellow(string(listing.Status)), age)
+
+			continue
+		}
+
+		if !staleReviewsOnly {
+			// pad before coloring, since the escape codes would throw off the widths
+			name := fmt.Sprintf("%-*s", nameWidth, listing.StackName)
+			status := fmt.Sprintf("%-*s", len(cloudformation.StackStatusUpdateRollbackCompleteCleanupInProgress), listing.Status)
+
+			fmt.Printf("  %s %s %s\n", colors.Teal(name), stackStatusColor(listing.Status)(status), listing.Created.Local().Format(time.RFC3339))
+		}
+	}
+
+	if staleReviews > 0 {
+		fmt.Println(colors.Status(fmt.Sprintf("%d stack(s) in REVIEW_IN_PROGRESS hold only an unexecuted change set. Remove them with `aws cloudformation delete-stack --stack-name <name>`", staleReviews)))
+	}
+
+	return nil
+}
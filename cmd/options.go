@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/ui"
+	"github.com/blueseph/cirrus/utils"
+	"github.com/urfave/cli/v2"
+)
+
+const defaultMaxStackEvents = 1000
+
+var maxStackEventsFlag = &cli.IntFlag{
+	Name:  "max-stack-events",
+	Value: defaultMaxStackEvents,
+	Usage: "Fetches and retains at most `count` of the most recent stack events per poll. Only bounds what cirrus displays, not CloudFormation itself",
+}
+
+var shortTypesFlag = &cli.BoolFlag{
+	Name:  "short-types",
+	Usage: "Abbreviates resource types in the display, e.g. ELBv2::LoadBalancer for AWS::ElasticLoadBalancingV2::LoadBalancer. Exports keep the full types",
+}
+
+var waitStatesFlag = &cli.StringFlag{
+	Name:  "stack-status-wait-states",
+	Usage: "Stops watching with success once the stack reaches any of the comma separated `statuses`, e.g. UPDATE_COMPLETE_CLEANUP_IN_PROGRESS",
+}
+
+var regionFlag = &cli.StringFlag{
+	Name:    "region",
+	Aliases: []string{"r"},
+	EnvVars: []string{"AWS_REGION"},
+	Usage:   "Manages the stack in `region` instead of the region from AWS_REGION or the shared config",
+}
+
+var profileFlag = &cli.StringFlag{
+	Name:    "profile",
+	EnvVars: []string{"AWS_PROFILE"},
+	Usage:   "Uses the named `profile` of the shared AWS config instead of AWS_PROFILE or the default profile",
+}
+
+// parametersFlag and the flags after it are the parameter sources read by resolveParameters, shared by the commands that use it
+var parametersFlag = &cli.StringFlag{
+	Name:    "parameters",
+	Aliases: []string{"p"},
+	Value:   "./parameters.json",
+	Usage:   "Specifies location of parameters `file`",
+}
+
+var coerceParametersFlag = &cli.BoolFlag{
+	Name:    "coerce-parameters",
+	Aliases: []string{"parameters-type-coercion"},
+	Usage:   "Same as --strict-strings=false, so number and boolean values are converted in the tags file as well as the parameters file. Kept for compatibility",
+}
+
+var strictStringsFlag = &cli.BoolFlag{
+	Name:  "strict-strings",
+	Value: true,
+	Usage: "Requires every parameter and tag value be a string. With --strict-strings=false, number and boolean values are converted to strings",
+}
+
+var parametersEnvFileFlag = &cli.StringFlag{
+	Name:  "parameters-env-file",
+	Usage: "Reads parameters from a dotenv `file` of KEY=VALUE lines. Overrides the parameters file",
+}
+
+var parametersFromOutputsFileFlag = &cli.StringFlag{
+	Name:  "parameters-from-outputs-file",
+	Usage: "Reads another stack's outputs from `file`, a JSON object of output key to value, for use with --map",
+}
+
+var parameterFlag = &cli.StringSliceFlag{
+	Name:  "parameter",
+	Usage: "Sets a parameter as `Key=Value`, overriding every other parameter source. Repeatable",
+}
+
+var mapFlag = &cli.StringSliceFlag{
+	Name:  "map",
+	Usage: "Maps an output from --parameters-from-outputs-file to a parameter as `ParameterKey=OutputKey`. Overrides the parameters file. Repeatable",
+}
+
+var maskParamPatternFlag = &cli.StringSliceFlag{
+	Name:    "mask-param-pattern",
+	Aliases: []string{"parameters-mask-pattern"},
+	Usage:   "Masks the values of parameters whose key matches `pattern` in all output, like NoEcho parameters. A glob such as *Password*, or a regular expression between slashes. Repeatable",
+}
+
+var displayFlags = []cli.Flag{
+	&cli.BoolFlag{
+		Name:  "always-refresh",
+		Usage: "Redraws the display on every poll, even when nothing changed (for debugging)",
+	},
+	&cli.StringFlag{
+		Name:  "output",
+		Usage: "Specifies the output `format` (table, lines, compact, github, summary-table, json). Defaults to github in GitHub Actions, table in a terminal and lines otherwise",
+	},
+	&cli.BoolFlag{
+		Name:  "verbose-changes",
+		Usage: "Lists the changed properties and change sources behind each modified resource in the preview",
+	},
+	maxStackEventsFlag,
+	waitStatesFlag,
+	shortTypesFlag,
+	&cli.StringFlag{
+		Name:  "summary-json",
+		Usage: "Writes a JSON summary of the operation (status, duration, resource counts, outputs, root cause) to `file`, even when it fails",
+	},
+	&cli.StringFlag{
+		Name:  "log-file",
+		Usage: "Appends every row observed during the watch, change set and event rows alike, to `file` as newline-delimited JSON",
+	},
+	&cli.StringFlag{
+		Name:  "metrics-file",
+		Usage: "Writes Prometheus metrics of the operation (duration, success, resource counts) to `file`, even when it fails",
+	},
+	&cli.StringFlag{
+		Name:  "timeline-file",
+		Usage: "Writes the start and end time of each resource to `file` once the operation finishes",
+	},
+	&cli.StringFlag{
+		Name:  "timeline-format",
+		Value: data.TimelineFormatMermaid,
+		Usage: "Specifies the timeline `format` (mermaid, json)",
+	},
+}
+
+func displayOptions(c *cli.Context) (ui.Options, error) {
+	waitStates, err := data.ParseStackStatuses(utils.SplitList(c.String("stack-status-wait-states")))
+
+	return ui.Options{
+		AlwaysRefresh:  c.Bool("always-refresh"),
+		Output:         outputFormat(c.String("output")),
+		MaxStackEvents: c.Int("max-stack-events"),
+		ShortTypes:     c.Bool("short-types"),
+		VerboseChanges: c.Bool("verbose-changes"),
+		WaitStates:     waitStates,
+		LogFile:        c.String("log-file"),
+	}, err
+}
+
+// coerceStrings determines whether number and boolean values in parameter and tag files are converted to strings, which
+// applies to both so they can't accept different values. --coerce-parameters is the inverse of --strict-strings, so setting
+// both to disagree is an error rather than one silently winning
+func coerceStrings(c *cli.Context) (bool, error) {
+	coerce := !c.Bool("strict-strings")
+
+	if c.IsSet("coerce-parameters") {
+		if c.IsSet("strict-strings") && c.Bool("coerce-parameters") != coerce {
+			return false, errors.New(colors.Error("--coerce-parameters and --strict-strings disagree. --coerce-parameters is the same as --strict-strings=false, so pass only one"))
+		}
+
+		coerce = c.Bool("coerce-parameters")
+	}
+
+	return coerce, nil
+}
+
+// fileLocation returns the location of the file flag name. A default location that doesn't exist gives no location, so a
+// missing default file stays optional rather than failing as a typo of a similarly named file
+func fileLocation(c *cli.Context, name string) string {
+	location := c.String(name)
+
+	if !c.IsSet(name) {
+		if _, err := os.Stat(location); os.IsNotExist(err) {
+			return ""
+		}
+	}
+
+	return location
+}
+
+// routeNotices sends status messages to stderr for json output, so stdout carries the rows alone and can be piped to jq
+func routeNotices(c *cli.Context) {
+	if outputFormat(c.String("output")) == ui.OutputJSON {
+		colors.SetNotices(os.Stderr)
+	}
+}
+
+func outputFormat(output string) ui.OutputFormat {
+	if output != "" {
+		return ui.OutputFormat(output)
+	}
+
+	if ui.IsGitHubActions() {
+		return ui.OutputGitHub
+	}
+
+	if utils.IsTerminal(os.Stdout) {
+		return ui.OutputTable
+	}
+
+	return ui.OutputLines
+}
@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/urfave/cli/v2"
+)
+
+// useWorkingDirectory changes into the directory of location until the test ends, so default relative paths resolve there
+func useWorkingDirectory(t *testing.T, location string) {
+	previous, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("unable to get the working directory: %s", err)
+	}
+
+	if err := os.Chdir(filepath.Dir(location)); err != nil {
+		t.Fatalf("unable to change the working directory: %s", err)
+	}
+
+	t.Cleanup(func() {
+		os.Chdir(previous)
+	})
+}
+
+func TestMissingFilesAreOnlyTyposWhenGiven(t *testing.T) {
+	// a file named like the default, which a missing default must not be mistaken for a typo of
+	useWorkingDirectory(t, writeTempFile(t, "parameters.jsn", "[]"))
+
+	flags := []cli.Flag{
+		parametersFlag, coerceParametersFlag, strictStringsFlag, parametersEnvFileFlag, parametersFromOutputsFileFlag, parameterFlag, mapFlag,
+		&cli.StringFlag{Name: "tags", Value: "./tags.json"},
+		&cli.StringSliceFlag{Name: "tag"},
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		problem string
+	}{
+		{name: "default locations", args: []string{}},
+		{name: "given parameters location", args: []string{"--parameters", "parameters.json"}, problem: "Did you mean parameters.jsn?"},
+		{name: "given tags location", args: []string{"--tags", "tags.jsn"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := runWithFlags(t, flags, test.args, func(c *cli.Context) error {
+				if _, err := resolveParameters(c); err != nil {
+					return err
+				}
+
+				_, err := resolveTags(c)
+				return err
+			})
+
+			if test.problem == "" {
+				if err != nil {
+					t.Errorf("expected missing files to be optional, got %s", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.problem) {
+				t.Errorf("expected an error suggesting %q, got %v", test.problem, err)
+			}
+		})
+	}
+}
+
+func TestCoerceStrings(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected bool
+		conflict bool
+	}{
+		{args: []string{}, expected: false},
+		{args: []string{"--strict-strings=false"}, expected: true},
+		{args: []string{"--coerce-parameters"}, expected: true},
+		{args: []string{"--coerce-parameters=false"}, expected: false},
+		{args: []string{"--strict-strings=false", "--coerce-parameters"}, expected: true},
+		{args: []string{"--strict-strings", "--coerce-parameters"}, conflict: true},
+		{args: []string{"--strict-strings=false", "--coerce-parameters=false"}, conflict: true},
+	}
+
+	for _, test := range tests {
+		err := runWithFlags(t, []cli.Flag{coerceParametersFlag, strictStringsFlag}, test.args, func(c *cli.Context) error {
+			coerce, err := coerceStrings(c)
+
+			if test.conflict {
+				if err == nil {
+					t.Errorf("expected %v to disagree, got coercion %t", test.args, coerce)
+				}
+
+				return nil
+			}
+
+			if err != nil {
+				t.Errorf("unexpected error for %v: %s", test.args, err)
+			}
+
+			if coerce != test.expected {
+				t.Errorf("expected coerceStrings to be %t for %v, got %t", test.expected, test.args, coerce)
+			}
+
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("unable to parse %v: %s", test.args, err)
+		}
+	}
+}
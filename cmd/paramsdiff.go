@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/urfave/cli/v2"
+)
+
+// defaultParametersDiffExitCode follows diff, which exits with 1 on differences, but leaves 1 to errors so CI can tell them apart
+const defaultParametersDiffExitCode = 2
+
+var paramsDiffFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:     "stack",
+		Aliases:  []string{"s"},
+		Usage:    "Specifies stack name or stack ID",
+		Required: true,
+	},
+	parametersFlag,
+	coerceParametersFlag,
+	strictStringsFlag,
+	parametersEnvFileFlag,
+	parametersFromOutputsFileFlag,
+	parameterFlag,
+	mapFlag,
+	maskParamPatternFlag,
+	&cli.IntFlag{
+		Name:  "parameters-diff-exit-code",
+		Usage: "Exits with `code` when the deployed parameters differ from the given ones",
+		Value: defaultParametersDiffExitCode,
+	},
+	regionFlag,
+	profileFlag,
+}
+
+// ParamsDiffCommand returns the CLI construct that compares the deployed parameters of a stack with the intended ones
+var ParamsDiffCommand = &cli.Command{
+	Name:   "params-diff",
+	Usage:  "Compare the deployed parameters of a stack with the given ones without deploying, exiting non-zero when they differ",
+	Before: applyConfigDefaults,
+	Action: paramsDiffAction,
+	Flags:  paramsDiffFlags,
+}
+
+// ParametersDifferError is returned when the deployed parameters differ from the intended ones, and sets the exit code
+type ParametersDifferError struct {
+	StackName string
+	Keys      []string
+	ExitCode  int
+}
+
+func (err *ParametersDifferError) Error() string {
+	return colors.Error(fmt.Sprintf("The parameters of %s differ from the deployed ones: %s", err.StackName, strings.Join(err.Keys, ", ")))
+}
+
+func paramsDiffAction(c *cli.Context) error {
+	cfn.SetRegion(c.String("region"))
+	cfn.SetProfile(c.String("profile"))
+
+	parameters, err := resolveParameters(c)
+	if err != nil {
+		return err
+	}
+
+	maskPatterns, err := data.ParseMaskPatterns(c.StringSlice("mask-param-pattern"))
+	if err != nil {
+		return err
+	}
+
+	err = ParamsDiff(c.String("stack"), parameters, maskPatterns, c.Int("parameters-diff-exit-code"))
+
+	// differences are the expected outcome of a check, not a fatal error
+	var differErr *ParametersDifferError
+	if err != nil && !errors.As(err, &differErr) {
+		fmt.Println(colors.Error("Cirrus encountered a fatal error:"))
+	}
+
+	return err
+}
+
+// ParamsDiff prints the parameters whose deployed value differs from the given one, masking the values of NoEcho parameters and
+// those matching a mask pattern. Differences are returned as a ParametersDifferError with the exit code. NoEcho values can't
+// be read back from CloudFormation, so those parameters are listed as not compared
+func ParamsDiff(stackName string, parameters []cloudformation.Parameter, maskPatterns []*regexp.Regexp, exitCode int) error {
+	err := cfn.VerifyAWSCredentials()
+	if err != nil {
+		return err
+	}
+
+	stack, err := cfn.GetStack(stackName)
+	if err != nil {
+		return err
+	}
+
+	info := data.StackInfo{
+		StackName: *stack.Stacks[0].StackName,
+		StackID:   *stack.Stacks[0].StackId,
+	}
+
+	template, err := cfn.GetDeployedTemplate(info)
+	if err != nil {
+		return err
+	}
+
+	deployed := stack.Stacks[0].Parameters
+
+	// both sides are redacted, since a parameter masked by pattern rather than NoEcho is deployed in the clear
+	data.RedactParameters(append(append([]cloudformation.Parameter{}, deployed...), parameters...), template, maskPatterns)
+
+	if uncompared := data.UncomparedParameters(deployed, parameters); len(uncompared) > 0 {
+		fmt.Println(colors.Status("NoEcho values can't be read back, so these parameters were not compared: " + strings.Join(uncompared, ", ")))
+	}
+
+	diffs := data.DiffParameters(deployed, parameters)
+	if len(diffs) == 0 {
+		fmt.Println(colors.Success(fmt.Sprintf("The parameters of %s match the deployed ones", info.StackName)))
+		return nil
+	}
+
+	keys := make([]string, 0)
+	deployedValues := data.ParameterValues(deployed)
+
+	for _, diff := range diffs {
+		keys = append(keys, diff.Key)
+
+		fmt.Println(describeParameterDiff(diff, deployedValues))
+	}
+
+	return &ParametersDifferError{StackName: info.StackName, Keys: keys, ExitCode: exitCode}
+}
+
+// describeParameterDiff renders a differing parameter as one line. Sensitive values are masked by key, however short they are
+func describeParameterDiff(diff data.ParameterDiff, deployedValues map[string]string) string {
+	deployedValue := data.RedactParameter(diff.Key, diff.Deployed)
+	if _, ok := deployedValues[diff.Key]; !ok {
+		deployedValue = "(not deployed)"
+	}
+
+	return fmt.Sprintf("  %s %s → %s", colors.Teal(diff.Key), colors.Red(deployedValue), colors.Green(data.RedactParameter(diff.Key, diff.Local)))
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+)
+
+func TestDescribeParameterDiffMasksShortSecrets(t *testing.T) {
+	defer colors.SetEnabled(colors.Enabled())
+	colors.SetEnabled(false)
+
+	deployed := []cloudformation.Parameter{
+		{ParameterKey: aws.String("DbPassword"), ParameterValue: aws.String("pw1")},
+		{ParameterKey: aws.String("Size"), ParameterValue: aws.String("1")},
+	}
+	local := []cloudformation.Parameter{
+		{ParameterKey: aws.String("DbPassword"), ParameterValue: aws.String("pw2")},
+		{ParameterKey: aws.String("Size"), ParameterValue: aws.String("2")},
+	}
+
+	patterns, err := data.ParseMaskPatterns([]string{"*Password*"})
+	if err != nil {
+		t.Fatalf("unable to parse the mask pattern: %s", err)
+	}
+
+	data.RedactParameters(append(append([]cloudformation.Parameter{}, deployed...), local...), data.Template{}, patterns)
+	defer data.RedactParameters(nil, data.Template{}, nil)
+
+	deployedValues := data.ParameterValues(deployed)
+	lines := make([]string, 0)
+
+	for _, diff := range data.DiffParameters(deployed, local) {
+		lines = append(lines, describeParameterDiff(diff, deployedValues))
+	}
+
+	output := strings.Join(lines, "\n")
+
+	if strings.Contains(output, "pw1") || strings.Contains(output, "pw2") {
+		t.Errorf("expected the short secret to be masked, got %s", output)
+	}
+
+	if !strings.Contains(output, "DbPassword **** → ****") {
+		t.Errorf("expected the secret masked on both sides, got %s", output)
+	}
+
+	if !strings.Contains(output, "Size 1 → 2") {
+		t.Errorf("expected other values in the clear, got %s", output)
+	}
+}
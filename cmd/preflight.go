@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/urfave/cli/v2"
+)
+
+var preflightFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "template",
+		Aliases: []string{"t"},
+		Value:   "./template.yaml",
+		Usage:   "Specifies location of template `file`",
+	},
+	&cli.StringFlag{
+		Name:    "parameters",
+		Aliases: []string{"p"},
+		Value:   "./parameters.json",
+		Usage:   "Specifies location of parameters `file`",
+	},
+	coerceParametersFlag,
+	strictStringsFlag,
+	&cli.StringFlag{
+		Name:  "tags",
+		Value: "./tags.json",
+		Usage: "Specifies location of tags `file`",
+	},
+	&cli.StringFlag{
+		Name:  "parameters-schema-file",
+		Usage: "Also validates the parameters against the JSON schema in `file`",
+	},
+	regionFlag,
+	profileFlag,
+}
+
+// PreflightCommand returns the CLI construct that validates a template with its parameters and tags without deploying
+var PreflightCommand = &cli.Command{
+	Name:   "preflight",
+	Usage:  "Validate a template, its parameters and its tags together without creating anything",
+	Before: applyConfigDefaults,
+	Action: preflightAction,
+	Flags:  preflightFlags,
+}
+
+func preflightAction(c *cli.Context) error {
+	cfn.SetRegion(c.String("region"))
+	cfn.SetProfile(c.String("profile"))
+
+	coerce, err := coerceStrings(c)
+	if err != nil {
+		return err
+	}
+
+	err = Preflight(c.String("template"), fileLocation(c, "parameters"), fileLocation(c, "tags"), c.String("parameters-schema-file"), coerce)
+	if err != nil {
+		fmt.Println(colors.Error("Cirrus encountered a fatal error:"))
+		return err
+	}
+
+	return nil
+}
+
+// Preflight runs every check on a template and its parameter and tag files, reporting all problems at once. It fails if any check does
+func Preflight(templateLocation string, parametersLocation string, tagsLocation string, schemaLocation string, coerce bool) error {
+	problems := make([]string, 0)
+
+	_, err := data.GetTags(tagsLocation, coerce)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	parameters, parametersErr := data.GetParameters(parametersLocation, coerce)
+	if parametersErr != nil {
+		problems = append(problems, parametersErr.Error())
+	}
+
+	if schemaLocation != "" && parametersErr == nil {
+		if err := data.ValidateParametersSchema(schemaLocation, parameters); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+
+	// the remaining checks need the template, but the problems found so far are still reported
+	template, err := ioutil.ReadFile(templateLocation)
+	if err != nil {
+		problems = append(problems, colors.Error(fmt.Sprintf("Unable to read template %s: %s", templateLocation, err.Error())))
+	} else {
+		problems = append(problems, checkTemplate(template, parameters, parametersErr == nil)...)
+	}
+
+	if len(problems) > 0 {
+		for _, problem := range problems {
+			fmt.Println(problem)
+		}
+
+		return errors.New(colors.Error(fmt.Sprintf("Preflight found %d problem(s)", len(problems))))
+	}
+
+	fmt.Println(colors.Success("Template, parameters and tags passed every check"))
+
+	return nil
+}
+
+// checkTemplate parses the template, checks the parameters against it when they loaded, and validates it with CloudFormation,
+// returning every problem found
+func checkTemplate(template []byte, parameters []cloudformation.Parameter, parametersLoaded bool) []string {
+	problems := make([]string, 0)
+
+	parsed, err := data.ParseTemplate(template)
+	if err != nil {
+		problems = append(problems, err.Error())
+	} else if parametersLoaded {
+		for _, problem := range data.CheckParameters(parsed, parameters) {
+			problems = append(problems, colors.Error(problem))
+		}
+	}
+
+	if err := cfn.VerifyAWSCredentials(); err != nil {
+		problems = append(problems, err.Error())
+	} else if _, err := cfn.ValidateTemplate(string(template), ""); err != nil {
+		problems = append(problems, colors.Error("CloudFormation rejected the template: "+err.Error()))
+	}
+
+	return problems
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPreflightReportsEveryProblemWhenTheTemplateIsMissing(t *testing.T) {
+	tags := writeTempFile(t, "tags.json", `[{"Key": "aws:team", "Value": "cirrus"}]`)
+	parameters := writeTempFile(t, "parameters.json", `{"not": "a list"}`)
+	template := filepath.Join(filepath.Dir(tags), "template.yaml")
+
+	err := Preflight(template, parameters, tags, "", false)
+	if err == nil {
+		t.Fatalf("expected preflight to fail")
+	}
+
+	if !strings.Contains(err.Error(), "Preflight found 3 problem(s)") {
+		t.Errorf("expected the tag, parameter and template problems together, got %s", err)
+	}
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/ui"
+	"github.com/urfave/cli/v2"
+)
+
+// finishAction reports the result of an operation, runs its exports and hooks, and determines the command's error
+func finishAction(c *cli.Context, result data.DeployResult, err error) error {
+	// the hook follows the outcome of the operation, not of reporting it
+	operationErr := err
+
+	// json output keeps stdout for the rows alone; --summary-json carries the rest
+	if ui.OutputFormat(c.String("output")) != ui.OutputJSON {
+		printResult(result)
+	}
+
+	if summaryErr := writeSummary(c, result, err == nil); summaryErr != nil && err == nil {
+		err = summaryErr
+	}
+
+	if metricsErr := writeMetrics(c, result, err == nil); metricsErr != nil && err == nil {
+		err = metricsErr
+	}
+
+	if exportErr := exportResult(c, result); exportErr != nil && err == nil {
+		err = exportErr
+	}
+
+	if hookErr := runHook(c, result, operationErr); hookErr != nil && err == nil {
+		err = hookErr
+	}
+
+	if err != nil {
+		colors.Notice(colors.Error("Cirrus encountered a fatal error:"))
+		return err
+	}
+
+	return nil
+}
+
+// writeSummary writes the JSON summary of the operation requested with --summary-json, whether or not it succeeded
+func writeSummary(c *cli.Context, result data.DeployResult, succeeded bool) error {
+	location := c.String("summary-json")
+	if location == "" {
+		return nil
+	}
+
+	if result.StackName == "" {
+		result.StackName = c.String("stack")
+	}
+
+	summary, err := json.MarshalIndent(data.Summarize(result, succeeded), "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(location, summary, 0644)
+}
+
+// writeMetrics writes the Prometheus metrics of the operation to --metrics-file. The file is replaced in one step so a
+// textfile collector never reads it half written
+func writeMetrics(c *cli.Context, result data.DeployResult, succeeded bool) error {
+	location := c.String("metrics-file")
+	if location == "" {
+		return nil
+	}
+
+	if result.StackName == "" {
+		result.StackName = c.String("stack")
+	}
+
+	metrics := data.FormatMetrics(data.Summarize(result, succeeded))
+
+	err := ioutil.WriteFile(location+".tmp", []byte(metrics), 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(location+".tmp", location)
+}
+
+// exportResult writes the exports requested by flags for an executed operation
+func exportResult(c *cli.Context, result data.DeployResult) error {
+	if !result.Executed {
+		return nil
+	}
+
+	if location := c.String("timeline-file"); location != "" {
+		file, err := os.Create(location)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		err = data.ExportTimeline(result.Rows, file, c.String("timeline-format"))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// withStackDetails adds the outputs and tags of the deployed stack to the result of an executed operation
+func withStackDetails(result data.DeployResult) (data.DeployResult, error) {
+	stack, err := cfn.GetStack(result.StackID)
+	if err != nil {
+		return result, err
+	}
+
+	result.Outputs = data.OutputValues(stack.Stacks[0].Outputs)
+	result.Tags = data.TagValues(stack.Stacks[0].Tags)
+
+	return result, nil
+}
+
+// printTagChecks reports whether each intended stack tag was applied
+func printTagChecks(checks []data.TagCheck) {
+	if len(checks) == 0 {
+		return
+	}
+
+	fmt.Println(colors.Status("Tags:"))
+	for _, check := range checks {
+		switch {
+		case check.Matched():
+			fmt.Printf("  %s %s = %s\n", colors.Green("✓"), colors.Teal(check.Key), check.Intended)
+		case check.Found:
+			fmt.Printf("  %s %s = %s, expected %s\n", colors.Warning("≠"), colors.Teal(check.Key), check.Applied, check.Intended)
+		default:
+			fmt.Printf("  %s %s missing, expected %s\n", colors.Red("✗"), colors.Teal(check.Key), check.Intended)
+		}
+	}
+}
+
+func printResult(result data.DeployResult) {
+	if !result.Executed {
+		return
+	}
+
+	fmt.Println(colors.Status(fmt.Sprintf("%s finished in %s after %s", result.StackName, result.Status, result.Duration.Round(time.Second))))
+
+	printOutputs(result.Outputs)
+	printTagChecks(result.TagChecks)
+}
+
+func printOutputs(outputs map[string]string) {
+	if len(outputs) == 0 {
+		return
+	}
+
+	keys := make([]string, 0)
+	for key := range outputs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	fmt.Println(colors.Status("Outputs:"))
+	for _, key := range keys {
+		fmt.Printf("  %s = %s\n", colors.Teal(key), outputs[key])
+	}
+}
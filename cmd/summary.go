@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"sort"
+
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/ui"
+	"github.com/urfave/cli/v2"
+)
+
+var summaryFlags = []cli.Flag{
+	&cli.StringFlag{
+		Name:    "template",
+		Aliases: []string{"t"},
+		Value:   "./template.yaml",
+		Usage:   "Specifies location of template `file`",
+	},
+	&cli.StringFlag{
+		Name:  "output",
+		Value: string(ui.OutputText),
+		Usage: "Specifies the output `format` (text, json)",
+	},
+}
+
+// SummaryCommand returns the CLI construct that prints a structural summary of a template
+var SummaryCommand = &cli.Command{
+	Name:   "summary",
+	Usage:  "Summarize the resources, parameters, outputs, and capabilities of a CloudFormation template",
+	Before: applyConfigDefaults,
+	Action: summaryAction,
+	Flags:  summaryFlags,
+}
+
+func summaryAction(c *cli.Context) error {
+	template, err := ioutil.ReadFile(c.String("template"))
+	if err != nil {
+		return err
+	}
+
+	err = Summary(template, ui.OutputFormat(c.String("output")))
+	if err != nil {
+		fmt.Println(colors.Error("Cirrus encountered a fatal error:"))
+		return err
+	}
+
+	return nil
+}
+
+// Summary parses a template and prints its resource counts, parameters, outputs, and required capabilities
+func Summary(template []byte, output ui.OutputFormat) error {
+	parsed, err := data.ParseTemplate(template)
+	if err != nil {
+		return err
+	}
+
+	summary := data.SummarizeTemplate(parsed)
+
+	switch output {
+	case ui.OutputJSON:
+		encoded, err := json.MarshalIndent(summary, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		fmt.Println(string(encoded))
+	case ui.OutputText:
+		printSummary(summary)
+	default:
+		return errors.New(colors.Error(fmt.Sprintf("Unknown output format %s. Expected text or json", output)))
+	}
+
+	return nil
+}
+
+func printSummary(summary data.TemplateSummary) {
+	types := make([]string, 0)
+	for resourceType := range summary.Resources {
+		types = append(types, resourceType)
+	}
+
+	sort.Strings(types)
+
+	fmt.Println(colors.Status(fmt.Sprintf("Resources (%d)", summary.ResourceCount)))
+	for _, resourceType := range types {
+		fmt.Printf("  %-50s %d\n", resourceType, summary.Resources[resourceType])
+	}
+
+	fmt.Println(colors.Status(fmt.Sprintf("Parameters (%d)", len(summary.Parameters))))
+	for _, parameter := range summary.Parameters {
+		fmt.Println("  " + parameter)
+	}
+
+	fmt.Println(colors.Status(fmt.Sprintf("Outputs (%d)", len(summary.Outputs))))
+	for _, output := range summary.Outputs {
+		fmt.Println("  " + output)
+	}
+
+	fmt.Println(colors.Status(fmt.Sprintf("Capabilities (%d)", len(summary.Capabilities))))
+	for _, capability := range summary.Capabilities {
+		fmt.Println("  " + string(capability))
+	}
+}
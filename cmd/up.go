@@ -1,18 +1,20 @@
 package cmd
 
 import (
-	"bufio"
+	"errors"
 	"fmt"
 	"io/ioutil"
-	"os"
+	"regexp"
+	"sort"
+	"strings"
 	"time"
-	"unicode"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/blueseph/cirrus/cfn"
 	"github.com/blueseph/cirrus/colors"
 	"github.com/blueseph/cirrus/data"
 	"github.com/blueseph/cirrus/ui"
+	"github.com/blueseph/cirrus/utils"
 	"github.com/urfave/cli/v2"
 )
 
@@ -24,15 +26,51 @@ var upFlags = []cli.Flag{
 		Usage:   "Specifies location of template `file`",
 	},
 	&cli.StringFlag{
-		Name:    "parameters",
-		Aliases: []string{"p"},
-		Value:   "./parameters.json",
-		Usage:   "Specifies location of parameters `file`",
+		Name:  "template-url",
+		Usage: "Deploys the template in S3 at `url`, s3://bucket/key or https://, instead of a local template. For templates over the inline size limit",
+	},
+	parametersFlag,
+	coerceParametersFlag,
+	strictStringsFlag,
+	parametersEnvFileFlag,
+	&cli.StringFlag{
+		Name:  "parameters-schema-file",
+		Usage: "Validates the parameters against the JSON schema in `file` before deploying",
+	},
+	parametersFromOutputsFileFlag,
+	parameterFlag,
+	mapFlag,
+	maskParamPatternFlag,
+	&cli.BoolFlag{
+		Name:  "strict-parameters",
+		Usage: "Fails when a parameter is given that the template doesn't declare. Otherwise such parameters are reported and left out",
+	},
+	&cli.BoolFlag{
+		Name:  "edit-parameters",
+		Usage: "Opens the resolved parameters as JSON in $EDITOR to adjust before deploying",
 	},
 	&cli.StringFlag{
 		Name:  "tags",
 		Value: "./tags.json",
-		Usage: "Specifies location of tags `file`",
+		Usage: "Specifies location of tags `file`. A change set has a single set of tags, which become the stack's tags when it executes, so the change set can't be tagged apart from the stack. Without any tags, an update keeps the stack's tags",
+	},
+	&cli.BoolFlag{
+		Name:  "replace-tags",
+		Usage: "Replaces the stack's tags with the given ones even when none are given, removing them all. Otherwise an update without tags keeps the stack's tags",
+	},
+	&cli.StringFlag{
+		Name:  "stack-policy",
+		Value: "./stack-policy.json",
+		Usage: "Specifies location of the stack policy `file`, set once the deploy succeeds. Without the file the stack keeps its policy",
+	},
+	&cli.StringSliceFlag{
+		Name:    "capability",
+		Aliases: []string{"capabilities"},
+		Usage:   "Grants the change set only the given `capability` (CAPABILITY_IAM, CAPABILITY_NAMED_IAM, CAPABILITY_AUTO_EXPAND) instead of all of them. Repeatable or comma separated",
+	},
+	&cli.StringSliceFlag{
+		Name:  "tag",
+		Usage: "Sets a tag as `Key=Value`, overriding the tags file. Repeatable",
 	},
 	&cli.StringFlag{
 		Name:     "stack",
@@ -50,114 +88,813 @@ var upFlags = []cli.Flag{
 		Aliases: []string{"o"},
 		Usage:   "Overwrites existing empty (0 resource) stacks before updating",
 	},
+	&cli.BoolFlag{
+		Name:  "exit-on-cleanup",
+		Usage: "Stops watching once an update reaches cleanup. Resources from the prior version may still be deleting",
+	},
+	&cli.StringFlag{
+		Name:  "changeset-description",
+		Usage: "Describes the change set in the console. Defaults to the subject of the current git commit, if any",
+	},
+	&cli.BoolFlag{
+		Name:  "parameters-default-from-deployed",
+		Usage: "Keeps the deployed value of every parameter not otherwise provided",
+	},
+	&cli.BoolFlag{
+		Name:  "show-property-values",
+		Usage: "Shows the values of each changed property before and after the change in the preview, with masked parameters redacted",
+	},
+	&cli.BoolFlag{
+		Name:  "validate",
+		Usage: "Validates the template with CloudFormation before creating the change set, listing its parameters and required capabilities",
+	},
+	&cli.BoolFlag{
+		Name:  "dry-run",
+		Usage: "Creates and prints the change set, then deletes it without executing",
+	},
+	&cli.BoolFlag{
+		Name:  "import-existing",
+		Usage: "Imports resources that already exist instead of failing to create them",
+	},
+	&cli.BoolFlag{
+		Name:  "detect-no-op-update",
+		Usage: "Skips the deploy when the template, parameters and tags match the deployed stack",
+	},
+	&cli.BoolFlag{
+		Name:  "confirm-replacements-individually",
+		Usage: "Asks to confirm each resource replacement before deploying",
+	},
+	&cli.BoolFlag{
+		Name:  "disable-rollback",
+		Usage: "Leaves a failed stack in CREATE_FAILED or UPDATE_FAILED instead of rolling back, so the failed resources can be investigated",
+	},
+	&cli.BoolFlag{
+		Name:  "fail-on-replacement",
+		Usage: "Aborts before executing a change set that will or may replace any resource",
+	},
+	&cli.BoolFlag{
+		Name:    "yes",
+		Aliases: []string{"y"},
+		Usage:   "Skips confirmation prompts, including the execute prompt in table and lines output",
+	},
+	&cli.StringFlag{
+		Name:  "expect",
+		Usage: "Aborts an update unless exactly the expected `scope` (template, parameters, both) changed",
+	},
+	&cli.BoolFlag{
+		Name:  "stack-resource-limit-check",
+		Usage: "Warns when the deploy would take the stack past the resources per stack quota, read from Service Quotas",
+	},
+	&cli.IntFlag{
+		Name:  "stack-resource-limit",
+		Usage: "Checks against `count` resources per stack instead of reading the quota from Service Quotas",
+	},
+	&cli.BoolFlag{
+		Name:  "force",
+		Usage: "Deploys even when another operation is already in progress on the stack",
+	},
+	regionFlag,
+	profileFlag,
+}
+
+// UpInput holds everything needed to bring a stack up
+type UpInput struct {
+	StackName  string
+	Overwrite  bool
+	Template   []byte
+	Tags       []cloudformation.Tag
+	Parameters []cloudformation.Parameter
+	Expect     data.ChangeScope
+	DetectNoOp bool
+	ChangeSet  cfn.ChangeSetOptions
+	Display    ui.Options
+
+	// StackPolicy is set on the stack once the deploy succeeds. Empty keeps the stack's policy
+	StackPolicy string
+
+	// ConfirmReplacements asks to confirm each replacement, unless Yes is set
+	ConfirmReplacements bool
+	Yes                 bool
+
+	// FailOnReplacement aborts before executing a change set that will or may replace any resource
+	FailOnReplacement bool
+
+	// ShowPropertyValues shows the values of changed properties before and after the change in the preview
+	ShowPropertyValues bool
+
+	// Validate checks the template with CloudFormation before creating the change set
+	Validate bool
+
+	// DryRun prints the change set and deletes it instead of executing it
+	DryRun bool
+
+	// MaskPatterns match the keys of parameters to mask in output, on top of those declared NoEcho
+	MaskPatterns []*regexp.Regexp
+
+	// StrictParameters fails on parameters the template doesn't declare instead of leaving them out with a warning
+	StrictParameters bool
+
+	// DefaultFromDeployed keeps the deployed value of any parameter not in Parameters
+	DefaultFromDeployed bool
+
+	// Force skips the check for an operation already in progress on the stack
+	Force bool
+
+	// CheckResourceLimit warns when the deploy would exceed ResourceLimit, or the quota from Service Quotas when it's zero
+	CheckResourceLimit bool
+	ResourceLimit      int
 }
 
 // UpCommand returns the CLI construct that uploads a template to CloudFormation and watches the response
 var UpCommand = &cli.Command{
 	Name:   "up",
 	Usage:  "Deploy a CloudFormation template and watch stack events",
+	Before: applyConfigDefaults,
 	Action: upAction,
-	Flags:  upFlags,
+	Flags:  append(append(upFlags, displayFlags...), hookFlags...),
 }
 
 func upAction(c *cli.Context) error {
-	template, err := ioutil.ReadFile(c.String("template"))
+	cfn.SetRegion(c.String("region"))
+	cfn.SetProfile(c.String("profile"))
+	routeNotices(c)
+
+	templateURL, err := resolveTemplateURL(c)
+	if err != nil {
+		return err
+	}
+
+	var template []byte
+	if templateURL == "" {
+		template, err = ioutil.ReadFile(c.String("template"))
+		if err != nil {
+			return err
+		}
+	}
+
+	tags, err := resolveTags(c)
+	if err != nil {
+		return err
+	}
+
+	parameters, err := resolveParameters(c)
 	if err != nil {
 		return err
 	}
 
-	tags, err := data.GetTags(c.String("tags"))
+	if c.Bool("edit-parameters") {
+		coerce, err := coerceStrings(c)
+		if err != nil {
+			return err
+		}
+
+		parameters, err = editParameters(parameters, coerce)
+		if err != nil {
+			return err
+		}
+	}
+
+	if schema := c.String("parameters-schema-file"); schema != "" {
+		err = data.ValidateParametersSchema(schema, parameters)
+		if err != nil {
+			return err
+		}
+	}
+
+	maskPatterns, err := data.ParseMaskPatterns(c.StringSlice("mask-param-pattern"))
 	if err != nil {
 		return err
 	}
 
-	parameters, err := data.GetParameters(c.String("parameters"))
+	stackPolicy, err := data.GetStackPolicy(c.String("stack-policy"))
 	if err != nil {
 		return err
 	}
 
-	stack := c.String("stack")
-	overwrite := c.Bool("overwrite")
+	capabilities, err := data.ParseCapabilities(utils.SplitList(strings.Join(c.StringSlice("capability"), ",")))
+	if err != nil {
+		return err
+	}
 
-	err = Up(stack, overwrite, template, tags, parameters)
+	options, err := displayOptions(c)
 	if err != nil {
-		fmt.Println(colors.Error("Cirrus encountered a fatal error:"))
 		return err
 	}
 
-	return nil
+	options.ExitOnCleanup = c.Bool("exit-on-cleanup")
+	options.Execute.DisableRollback = c.Bool("disable-rollback")
+	options.AutoApprove = c.Bool("yes")
+
+	input := UpInput{
+		StackName:  c.String("stack"),
+		Overwrite:  c.Bool("overwrite"),
+		Template:   template,
+		Tags:       tags,
+		Parameters: parameters,
+		Expect:     data.ChangeScope(c.String("expect")),
+		DetectNoOp: c.Bool("detect-no-op-update"),
+
+		StackPolicy:         stackPolicy,
+		ConfirmReplacements: c.Bool("confirm-replacements-individually"),
+		FailOnReplacement:   c.Bool("fail-on-replacement"),
+		Yes:                 c.Bool("yes"),
+		Force:               c.Bool("force"),
+		DefaultFromDeployed: c.Bool("parameters-default-from-deployed"),
+		StrictParameters:    c.Bool("strict-parameters"),
+		DryRun:              c.Bool("dry-run"),
+		Validate:            c.Bool("validate"),
+		ShowPropertyValues:  c.Bool("show-property-values"),
+		MaskPatterns:        maskPatterns,
+		CheckResourceLimit:  c.Bool("stack-resource-limit-check") || c.IsSet("stack-resource-limit"),
+		ResourceLimit:       c.Int("stack-resource-limit"),
+		ChangeSet: cfn.ChangeSetOptions{
+			ImportExisting: c.Bool("import-existing"),
+			Description:    changeSetDescription(c.String("changeset-description")),
+			TemplateURL:    templateURL,
+			ReplaceTags:    c.Bool("replace-tags"),
+			Capabilities:   capabilities,
+		},
+		Display: options,
+	}
+
+	result, err := Up(input)
+
+	return finishAction(c, result, err)
+}
+
+// resolveTemplateURL validates --template-url, which replaces --template. Options that compare the local template with the
+// deployed one need the template body, so they can't be combined with it
+func resolveTemplateURL(c *cli.Context) (string, error) {
+	raw := c.String("template-url")
+	if raw == "" {
+		return "", nil
+	}
+
+	if c.IsSet("template") {
+		return "", errors.New(colors.Error("--template and --template-url cannot be used together"))
+	}
+
+	for _, name := range []string{"parameters-default-from-deployed", "detect-no-op-update", "expect"} {
+		if c.IsSet(name) {
+			return "", errors.New(colors.Error(fmt.Sprintf("--%s needs a local template and cannot be used with --template-url", name)))
+		}
+	}
+
+	return data.NormalizeTemplateURL(raw)
+}
+
+// resolveParameters reads every local parameter source and merges them, each overriding the ones before it
+func resolveParameters(c *cli.Context) ([]cloudformation.Parameter, error) {
+	coerce, err := coerceStrings(c)
+	if err != nil {
+		return nil, err
+	}
+
+	fromFile, err := data.GetParameters(fileLocation(c, "parameters"), coerce)
+	if err != nil {
+		return nil, err
+	}
+
+	fromEnv := make([]cloudformation.Parameter, 0)
+	if envFile := c.String("parameters-env-file"); envFile != "" {
+		fromEnv, err = data.ParametersFromEnvFile(envFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	mapped := make([]cloudformation.Parameter, 0)
+	if outputs := c.String("parameters-from-outputs-file"); outputs != "" {
+		mapped, err = data.ParametersFromOutputs(outputs, c.StringSlice("map"))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	inline, err := data.ParametersFromFlags(c.StringSlice("parameter"))
+	if err != nil {
+		return nil, err
+	}
+
+	return data.MergeParameters(fromFile, fromEnv, mapped, inline), nil
+}
+
+// resolveTags reads the tags file and merges the tags given on the command line over it
+func resolveTags(c *cli.Context) ([]cloudformation.Tag, error) {
+	coerce, err := coerceStrings(c)
+	if err != nil {
+		return nil, err
+	}
+
+	fromFile, err := data.GetTags(fileLocation(c, "tags"), coerce)
+	if err != nil {
+		return nil, err
+	}
+
+	inline, err := data.TagsFromFlags(c.StringSlice("tag"))
+	if err != nil {
+		return nil, err
+	}
+
+	tags := data.MergeTags(fromFile, inline)
+
+	return tags, data.ValidateTags(tags)
 }
 
-// Up kicks off the stack creation lifecycle, creating a change set, confirming the change set, and tailing the events.
-func Up(stackName string, overwrite bool, template []byte, tags []cloudformation.Tag, parameters []cloudformation.Parameter) error {
-	changeSetName := stackName + "-" + fmt.Sprint(time.Now().Unix())
+// Up kicks off the stack creation lifecycle, creating a change set, confirming the change set, and tailing the events. The structured result of the operation is returned alongside any error
+func Up(input UpInput) (data.DeployResult, error) {
+	changeSetName := input.StackName + "-" + fmt.Sprint(time.Now().Unix())
 
 	info := data.StackInfo{
-		StackName:     stackName,
+		StackName:     input.StackName,
 		ChangeSetName: changeSetName,
 	}
 
+	// an unparseable template is left for CloudFormation to report, masking by pattern alone until then
+	template, parseErr := data.ParseTemplate(input.Template)
+	data.RedactParameters(input.Parameters, template, input.MaskPatterns)
+
+	// a template in S3 isn't read locally, so only CloudFormation can check its parameters
+	if parseErr == nil && input.ChangeSet.TemplateURL == "" {
+		parameters, err := checkUnusedParameters(template, input.Parameters, input.StrictParameters)
+		if err != nil {
+			return data.DeployResult{}, err
+		}
+
+		input.Parameters = parameters
+
+		if len(input.ChangeSet.Capabilities) > 0 {
+			warnMissingCapabilities(template, input.ChangeSet.Capabilities)
+		}
+	}
+
 	err := cfn.VerifyAWSCredentials()
 	if err != nil {
-		return err
+		return data.DeployResult{}, err
+	}
+
+	if input.Validate {
+		err := validateTemplate(input)
+		if err != nil {
+			return data.DeployResult{}, err
+		}
 	}
 
 	exists, err := cfn.DetermineIfStackExists(info.StackName)
 	if err != nil {
-		return err
+		return data.DeployResult{}, err
+	}
+
+	if exists && !input.Force {
+		err := verifyNoOperationInProgress(info)
+		if err != nil {
+			return data.DeployResult{}, err
+		}
 	}
 
 	empty := cfn.DetermineIfStackIsEmpty(info)
 
 	if exists && empty {
-		err := handleOverwrite(overwrite, exists, info)
+		err := handleOverwrite(input.Overwrite, exists, info)
 		if err != nil {
-			return err
+			return data.DeployResult{}, err
+		}
+	}
+
+	if input.DefaultFromDeployed && exists && !empty {
+		input.Parameters, err = defaultParametersFromDeployed(info, input)
+		if err != nil {
+			return data.DeployResult{}, err
+		}
+	}
+
+	if shouldKeepStackTags(exists, empty, input.Tags, input.ChangeSet.ReplaceTags) {
+		input.Tags, err = keepStackTags(info)
+		if err != nil {
+			return data.DeployResult{}, err
+		}
+	}
+
+	if input.DetectNoOp && exists {
+		noOp, err := isNoOpUpdate(info, input)
+		if err != nil {
+			return data.DeployResult{}, err
+		}
+
+		if noOp {
+			colors.Notice(colors.Status("No changes, skipping"))
+			return data.DeployResult{}, nil
+		}
+	}
+
+	if input.Expect != "" {
+		err := verifyChangeScope(info, exists, input)
+		if err != nil {
+			return data.DeployResult{}, err
 		}
 	}
 
-	fmt.Println(colors.Status("Creating change set..."))
-	changeSet, err := cfn.CreateChanges(info, template, tags, parameters, exists)
+	colors.Notice(colors.Status("Creating change set..."))
+	changeSet, err := cfn.CreateChanges(info, input.Template, input.Tags, input.Parameters, exists, input.ChangeSet)
+
+	var failure *cfn.ChangeSetFailedError
+	if errors.As(err, &failure) && failure.NoChanges() {
+		colors.Notice(colors.Status("No changes, skipping"))
+		return data.DeployResult{}, cfn.DeleteChangeSet(info)
+	}
+
 	if err != nil {
-		return err
+		return data.DeployResult{}, err
 	}
 
 	info.StackID = *changeSet.StackId
 
+	if input.ShowPropertyValues {
+		values, err := cfn.GetPropertyValues(info)
+		if err != nil {
+			colors.Notice(colors.Status("Property values are unavailable, showing the changes without them: " + err.Error()))
+		} else {
+			input.Display.PropertyValues = values
+		}
+	}
+
+	if input.CheckResourceLimit {
+		checkResourceLimit(info, exists, changeSet.Changes, input.ResourceLimit)
+	}
+
 	operation := cfn.StackOperationCreate
 	if exists {
 		operation = cfn.StackOperationUpdate
 	}
 
-	err = ui.DisplayChanges(info, changeSet, operation)
+	if input.FailOnReplacement {
+		err := refuseReplacements(info, changeSet, operation, input.Display)
+		if err != nil {
+			return data.DeployResult{}, err
+		}
+	}
+
+	if input.DryRun {
+		return data.DeployResult{}, dryRun(info, changeSet, operation, input.Display)
+	}
+
+	if input.ConfirmReplacements && !input.Yes {
+		err := confirmReplacements(info, changeSet.Changes)
+		if err != nil {
+			return data.DeployResult{}, err
+		}
+	}
 
-	return err
+	result, err := ui.DisplayChanges(info, changeSet, operation, input.Display)
+	if err != nil && result.Executed && input.Display.Execute.DisableRollback {
+		colors.Notice(colors.Status(fmt.Sprintf("Rollback is disabled, so %s is left as the deploy failed. Roll it back with `aws cloudformation rollback-stack --stack-name %s`, or fix the cause and deploy again", info.StackName, info.StackName)))
+	}
 
+	if err != nil || !result.Executed {
+		return result, err
+	}
+
+	// like the StackPolicyBody of UpdateStack, the new policy applies after the deploy, and the existing one governs it
+	if input.StackPolicy != "" {
+		err = cfn.SetStackPolicy(info, input.StackPolicy)
+		if err != nil {
+			return result, err
+		}
+
+		colors.Notice(colors.Status("Stack policy set"))
+	}
+
+	result, err = withStackDetails(result)
+	if err != nil {
+		return result, err
+	}
+
+	result.TagChecks = data.VerifyTags(input.Tags, result.Tags)
+
+	return result, nil
 }
 
-func askYesNoQuestion(question string) (bool, error) {
-	reader := bufio.NewReader(os.Stdin)
+// shouldKeepStackTags determines if an update should keep the tags of the deployed stack, which it does when no tags are given
+// unless they're to be replaced. A new or empty stack has no tags worth keeping
+func shouldKeepStackTags(exists bool, empty bool, tags []cloudformation.Tag, replaceTags bool) bool {
+	return exists && !empty && len(tags) == 0 && !replaceTags
+}
 
-	fmt.Println(question)
+// keepStackTags re-supplies the tags of the deployed stack for an update given no tags, so they're kept and verified like given ones
+func keepStackTags(info data.StackInfo) ([]cloudformation.Tag, error) {
+	stack, err := cfn.GetStack(info.StackName)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := stack.Stacks[0].Tags
+	if len(tags) > 0 {
+		colors.Notice(colors.Status(fmt.Sprintf("No tags given, keeping the stack's %d tag(s). Pass --replace-tags to remove them", len(tags))))
+	}
+
+	return tags, nil
+}
+
+// validateTemplate checks the template with CloudFormation and lists what it declares, failing before a change set is created
+// for a template that would be rejected anyway
+func validateTemplate(input UpInput) error {
+	colors.Notice(colors.Status("Validating template..."))
+
+	validation, err := cfn.ValidateTemplate(string(input.Template), input.ChangeSet.TemplateURL)
+	if err != nil {
+		msg := colors.Error("CloudFormation rejected the template: "+err.Error()) + " \n"
+		msg += colors.Docs("https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/template-anatomy.html")
+
+		return errors.New(msg)
+	}
+
+	keys := make([]string, 0)
+	for _, parameter := range validation.Parameters {
+		keys = append(keys, *parameter.ParameterKey)
+	}
+
+	sort.Strings(keys)
+
+	if len(keys) > 0 {
+		colors.Notice(colors.Status("Template declares parameters: " + strings.Join(keys, ", ")))
+	}
+
+	if len(validation.Capabilities) > 0 {
+		capabilities := make([]string, 0)
+		for _, capability := range validation.Capabilities {
+			capabilities = append(capabilities, string(capability))
+		}
+
+		message := "Template requires capabilities: " + strings.Join(capabilities, ", ")
+		if validation.CapabilitiesReason != nil {
+			message += " (" + *validation.CapabilitiesReason + ")"
+		}
+
+		colors.Notice(colors.Status(message))
+	}
 
-	for {
-		char, _, err := reader.ReadRune()
+	colors.Notice(colors.Success("Template is valid"))
 
+	return nil
+}
+
+// warnMissingCapabilities warns when the template's resources need a capability that wasn't granted, as CloudFormation will
+// refuse to create the change set
+func warnMissingCapabilities(template data.Template, granted []cloudformation.Capability) {
+	missing := data.MissingCapabilities(data.GetRequiredCapabilities(template), granted)
+	if len(missing) == 0 {
+		return
+	}
+
+	names := make([]string, 0)
+	for _, capability := range missing {
+		names = append(names, string(capability))
+	}
+
+	colors.Notice(colors.Status("The template's resources likely need " + strings.Join(names, ", ") + ", which --capability doesn't grant. The change set will fail without it"))
+}
+
+// checkUnusedParameters reports every given parameter the template doesn't declare at once. They're an error when strict, and
+// are otherwise left out, since CloudFormation would reject them
+func checkUnusedParameters(template data.Template, parameters []cloudformation.Parameter, strict bool) ([]cloudformation.Parameter, error) {
+	unused := data.UnusedParameters(template, parameters)
+	if len(unused) == 0 {
+		return parameters, nil
+	}
+
+	message := "Parameters not declared by the template: " + strings.Join(unused, ", ")
+
+	if strict {
+		return nil, errors.New(colors.Error(message))
+	}
+
+	colors.Notice(colors.Status(message + ". They will be ignored. Check them for typos, or use --strict-parameters to fail instead"))
+
+	return data.RemoveParameters(parameters, unused), nil
+}
+
+// checkResourceLimit warns when executing the changes would take the stack past the resources per stack quota
+func checkResourceLimit(info data.StackInfo, exists bool, changes []cloudformation.Change, limit int) {
+	if limit <= 0 {
+		quota, err := cfn.GetResourceQuota()
 		if err != nil {
-			return false, err
+			// the check is advisory, so a quota that can't be read falls back to the default rather than stopping the deploy
+			colors.Notice(colors.Status(fmt.Sprintf("Unable to read the resources per stack quota, checking against the default of %d: %s", quota, err)))
 		}
 
-		char = unicode.ToLower(char)
+		limit = quota
+	}
+
+	current := 0
+	if exists {
+		paginator := cfn.GetStackResources(info)
+		current = len(data.GetResourcesFromPaginator(cfn.Context(), &paginator))
+	}
+
+	projected := data.ProjectResourceCount(current, changes)
+	if projected > limit {
+		colors.Notice(colors.Error(fmt.Sprintf("%s would have %d resources after this deploy, over the quota of %d resources per stack. The deploy will likely fail", info.StackName, projected, limit)))
+	}
+}
+
+// changeSetDescription defaults the description to the current git commit subject, and truncates it to the API limit
+func changeSetDescription(description string) string {
+	if description == "" {
+		description = utils.GitCommitSubject()
+	}
+
+	if characters := []rune(description); len(characters) > cfn.MaxChangeSetDescriptionLength {
+		colors.Notice(colors.Status(fmt.Sprintf("Change set description truncated to %d characters", cfn.MaxChangeSetDescriptionLength)))
+		description = string(characters[:cfn.MaxChangeSetDescriptionLength])
+	}
+
+	return description
+}
+
+// verifyNoOperationInProgress refuses to deploy over an operation someone else already started on the stack
+func verifyNoOperationInProgress(info data.StackInfo) error {
+	stack, err := cfn.GetStack(info.StackName)
+	if err != nil {
+		return err
+	}
+
+	status := stack.Stacks[0].StackStatus
+	if !utils.ContainsStackStatus(data.PendingStackStatus, cloudformation.ResourceStatus(status)) {
+		return nil
+	}
+
+	started := *stack.Stacks[0].CreationTime
+	if stack.Stacks[0].LastUpdatedTime != nil {
+		started = *stack.Stacks[0].LastUpdatedTime
+	}
 
-		switch char {
-		case 'y':
-			return true, nil
-		case 'n':
-			return false, nil
-		default:
-			fmt.Println("Please enter Y/N")
+	message := fmt.Sprintf("%s is already in %s since %s. Watch it with `cirrus events --stack %s`, or deploy anyway with --force",
+		info.StackName, status, started.Local().Format(time.RFC1123), info.StackName)
+
+	return errors.New(colors.Error(message))
+}
+
+// confirmReplacements asks to confirm each replacement individually. Change sets are all-or-nothing, so declining any replacement
+// deletes the change set and aborts the deploy
+func confirmReplacements(info data.StackInfo, changes []cloudformation.Change) error {
+	declined := make([]string, 0)
+
+	for _, change := range changes {
+		resource := change.ResourceChange
+		if !data.IsResourceChange(change) || resource.Replacement != cloudformation.ReplacementTrue {
+			continue
 		}
+
+		question := fmt.Sprintf("Replace %s (%s)?", *resource.LogicalResourceId, *resource.ResourceType)
+		if properties := data.ReplacementCausingProperties(change); len(properties) > 0 {
+			question += " Caused by " + strings.Join(properties, ", ") + "."
+		}
+		question += " [Y/N]"
+
+		confirm, err := utils.AskYesNoQuestion(colors.Status(question))
+		if err != nil {
+			return err
+		}
+
+		if !confirm {
+			declined = append(declined, *resource.LogicalResourceId)
+		}
+	}
+
+	if len(declined) == 0 {
+		return nil
+	}
+
+	err := cfn.DeleteChangeSet(info)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("Declined replacing %s. Change sets are all-or-nothing, so the deploy was aborted", strings.Join(declined, ", "))
+
+	return errors.New(colors.Error(msg))
+}
+
+// refuseReplacements prints the change set and deletes it when it will or may replace any resource, so the replacements can be
+// reviewed without anything being executed. Only updates replace resources, so the stack itself is left in place
+func refuseReplacements(info data.StackInfo, changeSet *cloudformation.DescribeChangeSetResponse, operation cfn.StackOperation, options ui.Options) error {
+	replaced := data.ReplacedResources(changeSet.Changes)
+	if len(replaced) == 0 {
+		return nil
+	}
+
+	ui.PrintChanges(info, changeSet, operation, options)
+
+	err := cfn.DeleteChangeSet(info)
+	if err != nil {
+		return err
+	}
+
+	msg := fmt.Sprintf("The change set would replace %s. Aborted by --fail-on-replacement", strings.Join(replaced, ", "))
+
+	return errors.New(colors.Error(msg))
+}
+
+// dryRun prints the change set and deletes it. Creating a change set for a new stack leaves the stack in REVIEW_IN_PROGRESS,
+// so that stack is deleted too
+func dryRun(info data.StackInfo, changeSet *cloudformation.DescribeChangeSetResponse, operation cfn.StackOperation, options ui.Options) error {
+	ui.PrintChanges(info, changeSet, operation, options)
+
+	colors.Notice(colors.Status("Dry run, deleting the change set without executing it"))
+
+	err := cfn.DeleteChangeSet(info)
+	if err != nil {
+		return err
+	}
+
+	if operation == cfn.StackOperationCreate {
+		return cfn.DeleteStack(info, cfn.DeleteStackOptions{})
+	}
+
+	return nil
+}
+
+// defaultParametersFromDeployed fills in every parameter missing from the input with its deployed value
+func defaultParametersFromDeployed(info data.StackInfo, input UpInput) ([]cloudformation.Parameter, error) {
+	stack, err := cfn.GetStack(info.StackName)
+	if err != nil {
+		return nil, err
+	}
+
+	template, err := data.ParseTemplate(input.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	return data.DefaultToPreviousValues(input.Parameters, stack.Stacks[0].Parameters, data.GetTemplateParameterKeys(template)), nil
+}
+
+// isNoOpUpdate determines if an update would change nothing, with the template, parameters and tags all matching the deployed
+// stack. Capabilities only permit changes, so they aren't compared
+func isNoOpUpdate(info data.StackInfo, input UpInput) (bool, error) {
+	scope, err := determineChangeScope(info, input)
+	if err != nil {
+		return false, err
+	}
+
+	stack, err := cfn.GetStack(info.StackName)
+	if err != nil {
+		return false, err
+	}
+
+	return scope == data.ChangeScopeNone && !data.TagsDiffer(stack.Stacks[0].Tags, input.Tags), nil
+}
+
+// determineChangeScope compares the local template and parameters against the deployed stack
+func determineChangeScope(info data.StackInfo, input UpInput) (data.ChangeScope, error) {
+	deployedTemplate, err := cfn.GetTemplate(info)
+	if err != nil {
+		return "", err
+	}
+
+	stack, err := cfn.GetStack(info.StackName)
+	if err != nil {
+		return "", err
+	}
+
+	normalizedDeployed, err := cfn.NormalizeTemplate(deployedTemplate)
+	if err != nil {
+		return "", err
+	}
+
+	normalizedLocal, err := cfn.NormalizeTemplate(string(input.Template))
+	if err != nil {
+		return "", err
+	}
+
+	templateChanged := normalizedDeployed != normalizedLocal
+	parametersChanged := len(data.DiffParameters(stack.Stacks[0].Parameters, input.Parameters)) > 0
+
+	return data.DetermineChangeScope(templateChanged, parametersChanged), nil
+}
+
+func verifyChangeScope(info data.StackInfo, exists bool, input UpInput) error {
+	switch input.Expect {
+	case data.ChangeScopeTemplate, data.ChangeScopeParameters, data.ChangeScopeBoth:
+	default:
+		return errors.New(colors.Error(fmt.Sprintf("Unknown expected scope %s. Expected template, parameters, or both", input.Expect)))
+	}
+
+	if !exists {
+		colors.Notice(colors.Status("Stack does not exist yet. Skipping expected scope check"))
+		return nil
+	}
+
+	actual, err := determineChangeScope(info, input)
+	if err != nil {
+		return err
+	}
+
+	if actual != input.Expect {
+		return errors.New(colors.Error(fmt.Sprintf("Expected to change %s, but this deploy changes %s. Aborting", input.Expect, actual)))
 	}
+
+	return nil
 }
 
 func handleOverwrite(overwrite bool, exists bool, info data.StackInfo) error {
@@ -165,21 +902,29 @@ func handleOverwrite(overwrite bool, exists bool, info data.StackInfo) error {
 	confirm := overwrite
 
 	if !confirm {
-		confirm, err = askYesNoQuestion(colors.Status("Empty stack detected. Overwrite? [Y/N]"))
+		confirm, err = utils.AskYesNoQuestion(colors.Status("Empty stack detected. Overwrite? [Y/N]"))
 		if err != nil {
 			return err
 		}
 	}
 
 	if confirm {
-		fmt.Println(colors.Status("Deleting stack..."))
-		err := cfn.DeleteStackAndWait(info)
+		// the name stops resolving once the deletion completes, so wait on the stack ID instead
+		stack, err := cfn.GetStack(info.StackName)
+		if err != nil {
+			return err
+		}
+
+		info.StackID = *stack.Stacks[0].StackId
+
+		colors.Notice(colors.Status("Deleting stack..."))
+		err = cfn.DeleteStackAndWait(info)
 		exists = false
 		if err != nil {
 			return err
 		}
 	} else {
-		fmt.Println(colors.Status("User declined empty stack deletion. Terminating"))
+		colors.Notice(colors.Status("User declined empty stack deletion. Terminating"))
 		return nil
 	}
 
@@ -0,0 +1,113 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/data"
+	"github.com/urfave/cli/v2"
+)
+
+// writeTempFile writes contents to a file of the given name in a directory removed once the test ends, returning its path
+func writeTempFile(t *testing.T, name string, contents string) string {
+	dir, err := ioutil.TempDir("", "cirrus")
+	if err != nil {
+		t.Fatalf("unable to create a temporary directory: %s", err)
+	}
+
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	location := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(location, []byte(contents), 0644); err != nil {
+		t.Fatalf("unable to write %s: %s", location, err)
+	}
+
+	return location
+}
+
+// runWithFlags parses args against flags, as a command would, and calls action with the parsed context
+func runWithFlags(t *testing.T, flags []cli.Flag, args []string, action func(c *cli.Context) error) error {
+	app := &cli.App{
+		Flags:  flags,
+		Action: action,
+	}
+
+	return app.Run(append([]string{"cirrus"}, args...))
+}
+
+func TestResolveParametersPrecedence(t *testing.T) {
+	parametersFile := writeTempFile(t, "parameters.json", `[
+		{"ParameterKey": "Environment", "ParameterValue": "file"},
+		{"ParameterKey": "Size", "ParameterValue": "file"},
+		{"ParameterKey": "Name", "ParameterValue": "file"},
+		{"ParameterKey": "Owner", "ParameterValue": "file"}
+	]`)
+	envFile := writeTempFile(t, ".env", "Size=env\nName=env\nOwner=env\n")
+	outputsFile := writeTempFile(t, "outputs.json", `{"StackName": "outputs", "StackOwner": "outputs"}`)
+
+	flags := []cli.Flag{parametersFlag, coerceParametersFlag, strictStringsFlag, parametersEnvFileFlag, parametersFromOutputsFileFlag, parameterFlag, mapFlag}
+	args := []string{
+		"--parameters", parametersFile,
+		"--parameters-env-file", envFile,
+		"--parameters-from-outputs-file", outputsFile,
+		"--map", "Name=StackName", "--map", "Owner=StackOwner",
+		"--parameter", "Owner=flag",
+	}
+
+	var resolved []cloudformation.Parameter
+
+	err := runWithFlags(t, flags, args, func(c *cli.Context) error {
+		var err error
+		resolved, err = resolveParameters(c)
+
+		return err
+	})
+	if err != nil {
+		t.Fatalf("unable to resolve parameters: %s", err)
+	}
+
+	expected := map[string]string{
+		"Environment": "file",
+		"Size":        "env",
+		"Name":        "outputs",
+		"Owner":       "flag",
+	}
+
+	if got := data.ParameterValues(resolved); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestShouldKeepStackTags(t *testing.T) {
+	tags := []cloudformation.Tag{{Key: aws.String("team"), Value: aws.String("cirrus")}}
+
+	tests := []struct {
+		name        string
+		exists      bool
+		empty       bool
+		tags        []cloudformation.Tag
+		replaceTags bool
+		expected    bool
+	}{
+		{name: "update without tags", exists: true, expected: true},
+		{name: "update with tags", exists: true, tags: tags},
+		{name: "update replacing tags", exists: true, replaceTags: true},
+		{name: "create without tags"},
+		{name: "empty stack without tags", exists: true, empty: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := shouldKeepStackTags(test.exists, test.empty, test.tags, test.replaceTags); got != test.expected {
+				t.Errorf("expected shouldKeepStackTags to be %t, got %t", test.expected, got)
+			}
+		})
+	}
+}
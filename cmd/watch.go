@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/ui"
+	"github.com/blueseph/cirrus/utils"
+	"github.com/urfave/cli/v2"
+)
+
+var watchFlags = []cli.Flag{
+	&cli.StringSliceFlag{
+		Name:     "stack",
+		Aliases:  []string{"s"},
+		Usage:    "Specifies a stack name or stack ID to watch. Repeatable",
+		Required: true,
+	},
+	&cli.StringFlag{
+		Name:  "output",
+		Usage: "Specifies the output `format` (table, lines). Defaults to table in a terminal and lines otherwise",
+	},
+	maxStackEventsFlag,
+	shortTypesFlag,
+	regionFlag,
+	profileFlag,
+}
+
+// WatchCommand returns the CLI construct that watches the operations of several stacks in one display
+var WatchCommand = &cli.Command{
+	Name:   "watch",
+	Usage:  "Watch the operations of several stacks together, in a section per stack",
+	Before: applyConfigDefaults,
+	Action: watchAction,
+	Flags:  watchFlags,
+}
+
+func watchAction(c *cli.Context) error {
+	cfn.SetRegion(c.String("region"))
+	cfn.SetProfile(c.String("profile"))
+	routeNotices(c)
+
+	options := ui.Options{
+		Output:         outputFormat(c.String("output")),
+		MaxStackEvents: c.Int("max-stack-events"),
+		ShortTypes:     c.Bool("short-types"),
+	}
+
+	err := Watch(c.StringSlice("stack"), options)
+	if err != nil {
+		colors.Notice(colors.Error("Cirrus encountered a fatal error:"))
+		return err
+	}
+
+	return nil
+}
+
+// Watch follows every given stack until each reaches a terminal status, replaying the events of any operation in progress from
+// its start. Stacks that aren't being changed are shown with their status
+func Watch(stackNames []string, options ui.Options) error {
+	err := cfn.VerifyAWSCredentials()
+	if err != nil {
+		return err
+	}
+
+	watches := make([]ui.StackWatch, 0)
+
+	for _, stackName := range stackNames {
+		stack, err := cfn.GetStack(stackName)
+		if err != nil {
+			return err
+		}
+
+		watch := ui.StackWatch{
+			Info: data.StackInfo{
+				StackName: *stack.Stacks[0].StackName,
+				StackID:   *stack.Stacks[0].StackId,
+			},
+			Status: stack.Stacks[0].StackStatus,
+		}
+
+		if utils.ContainsStackStatus(data.PendingStackStatus, cloudformation.ResourceStatus(watch.Status)) {
+			latest, err := cfn.GetLatestStackEventTime(watch.Info)
+			if err != nil {
+				return err
+			}
+
+			watch.Since, err = operationCutoff(watch.Info, latest, options.MaxStackEvents)
+			if err != nil {
+				return err
+			}
+		}
+
+		watches = append(watches, watch)
+	}
+
+	return ui.WatchStacks(watches, options)
+}
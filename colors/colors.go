@@ -1,6 +1,14 @@
 package colors
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+
+	"github.com/blueseph/cirrus/utils"
+)
+
+//enabled is read on every call, so SetEnabled applies to output produced after flags are parsed
+var enabled = detectColor()
 
 var (
 	//Black tints colors black
@@ -20,41 +28,107 @@ var (
 	//White tints colors white
 	White = color("\033[1;37m%s\033[0m")
 
-	//ERROR prints a stylized error prefix
-	ERROR = White("[") + Red("ERROR") + White("]")
+	//ERROR prints a stylized error prefix. It's kept for compatibility, and follows the color settings and theme as they change
+	ERROR = prefix("ERROR", themed("error"))
 
-	//DOCS prints a stylized doc prefix
-	DOCS = White("[") + Magenta("DOCS") + White("]")
+	//DOCS prints a stylized doc prefix. Kept for compatibility, like ERROR
+	DOCS = prefix("DOCS", themed("docs"))
 
-	//STATUS prints a stylized status prefix
-	STATUS = White("[") + Teal("STATUS") + White("]")
+	//STATUS prints a stylized status prefix. Kept for compatibility, like ERROR
+	STATUS = prefix("STATUS", themed("status"))
 
-	//SUCCESS prints a stylized status prefix
-	SUCCESS = White("[") + Green("SUCCESS") + White("]")
+	//SUCCESS prints a stylized success prefix. Kept for compatibility, like ERROR
+	SUCCESS = prefix("SUCCESS", themed("success"))
 
 	//Error returns a formatted message with a stylized error prefix
-	Error = formatMessage(ERROR)
+	Error = formatMessage("ERROR", themed("error"))
 
 	//Docs returns a formatted message with a stylized docs prefix
-	Docs = formatMessage(DOCS)
+	Docs = formatMessage("DOCS", themed("docs"))
 
 	//Status returns a formatted message with a stylized "status" prefix
-	Status = formatMessage(STATUS)
+	Status = formatMessage("STATUS", themed("status"))
 
 	//Success returns a formatted message with a stylized success prefix
-	Success = formatMessage(SUCCESS)
+	Success = formatMessage("SUCCESS", themed("success"))
+
+	//Pending returns a formatted message with a stylized pending prefix
+	Pending = formatMessage("PENDING", themed("pending"))
+
+	//Warning tints text with the warning color of the theme
+	Warning = themed("warning")
+
+	//Replacement tints text with the replacement color of the theme
+	Replacement = themed("replacement")
 )
 
+//SetEnabled turns colored output on or off, overriding NO_COLOR and terminal detection
+func SetEnabled(on bool) {
+	enabled = on
+	refreshPrefixes()
+}
+
+//Enabled reports whether output is currently colored
+func Enabled() bool {
+	return enabled
+}
+
+//SetFlags applies the --color and --no-color flags over NO_COLOR and terminal detection
+func SetFlags(forceColor bool, noColor bool) {
+	enabled = resolveColor(forceColor, noColor, noColorSet(), utils.IsTerminal(os.Stdout))
+	refreshPrefixes()
+}
+
+//detectColor disables color when NO_COLOR is set to any value or stdout is not a terminal
+func detectColor() bool {
+	return resolveColor(false, false, noColorSet(), utils.IsTerminal(os.Stdout))
+}
+
+func noColorSet() bool {
+	_, ok := os.LookupEnv("NO_COLOR")
+
+	return ok
+}
+
+//resolveColor decides whether output is colored. --color forces it on, otherwise --no-color, NO_COLOR or a non-terminal stdout turn it off
+func resolveColor(forceColor bool, noColor bool, noColorEnv bool, terminal bool) bool {
+	if forceColor {
+		return true
+	}
+
+	if noColor || noColorEnv {
+		return false
+	}
+
+	return terminal
+}
+
 func color(colorString string) func(...interface{}) string {
 	sprint := func(args ...interface{}) string {
+		if !enabled {
+			return fmt.Sprint(args...)
+		}
+
 		return fmt.Sprintf(colorString,
 			fmt.Sprint(args...))
 	}
 	return sprint
 }
 
-func formatMessage(formatted string) func(string) string {
+func formatMessage(label string, tint func(...interface{}) string) func(string) string {
 	return func(message string) string {
-		return fmt.Sprintf("%s %s", formatted, message)
+		return fmt.Sprintf("%s %s", prefix(label, tint), message)
 	}
 }
+
+func prefix(label string, tint func(...interface{}) string) string {
+	return White("[") + tint(label) + White("]")
+}
+
+//refreshPrefixes recomputes the prefix variables once the color settings or theme change
+func refreshPrefixes() {
+	ERROR = prefix("ERROR", themed("error"))
+	DOCS = prefix("DOCS", themed("docs"))
+	STATUS = prefix("STATUS", themed("status"))
+	SUCCESS = prefix("SUCCESS", themed("success"))
+}
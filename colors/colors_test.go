@@ -0,0 +1,89 @@
+package colors
+
+import (
+	"os"
+	"testing"
+)
+
+func TestResolveColor(t *testing.T) {
+	tests := []struct {
+		name       string
+		forceColor bool
+		noColor    bool
+		noColorEnv bool
+		terminal   bool
+		expected   bool
+	}{
+		{name: "terminal", terminal: true, expected: true},
+		{name: "not a terminal", expected: false},
+		{name: "NO_COLOR on a terminal", noColorEnv: true, terminal: true, expected: false},
+		{name: "--no-color on a terminal", noColor: true, terminal: true, expected: false},
+		{name: "--color when not a terminal", forceColor: true, expected: true},
+		{name: "--color over NO_COLOR", forceColor: true, noColorEnv: true, expected: true},
+		{name: "--color over NO_COLOR on a terminal", forceColor: true, noColorEnv: true, terminal: true, expected: true},
+		{name: "--no-color with NO_COLOR", noColor: true, noColorEnv: true, terminal: true, expected: false},
+		{name: "--no-color when not a terminal", noColor: true, expected: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := resolveColor(test.forceColor, test.noColor, test.noColorEnv, test.terminal); got != test.expected {
+				t.Errorf("expected color %t, got %t", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestSetFlagsHonorsNoColor(t *testing.T) {
+	defer SetEnabled(enabled)
+
+	previous, set := os.LookupEnv("NO_COLOR")
+	defer func() {
+		if set {
+			os.Setenv("NO_COLOR", previous)
+		} else {
+			os.Unsetenv("NO_COLOR")
+		}
+	}()
+
+	os.Setenv("NO_COLOR", "")
+
+	SetFlags(false, false)
+	if Enabled() {
+		t.Errorf("expected NO_COLOR set to an empty value to disable color")
+	}
+
+	if got := Red("text"); got != "text" {
+		t.Errorf("expected plain text without color, got %q", got)
+	}
+
+	SetFlags(true, false)
+	if !Enabled() {
+		t.Errorf("expected --color to force color on over NO_COLOR")
+	}
+}
+
+func TestPrefixesFollowTheSettings(t *testing.T) {
+	restoreTheme(t)
+	defer SetEnabled(Enabled())
+
+	SetEnabled(false)
+
+	if ERROR != "[ERROR]" || DOCS != "[DOCS]" || STATUS != "[STATUS]" || SUCCESS != "[SUCCESS]" {
+		t.Errorf("expected plain prefixes without color, got %q %q %q %q", ERROR, DOCS, STATUS, SUCCESS)
+	}
+
+	SetEnabled(true)
+
+	if err := SetThemeColors([]string{"error=1;35"}); err != nil {
+		t.Fatalf("unable to set the theme color: %s", err)
+	}
+
+	if ERROR != White("[")+"\033[1;35mERROR\033[0m"+White("]") {
+		t.Errorf("expected the error prefix in the themed color, got %q", ERROR)
+	}
+
+	if Error("failed") != ERROR+" failed" {
+		t.Errorf("expected Error to use the same prefix, got %q", Error("failed"))
+	}
+}
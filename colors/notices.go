@@ -0,0 +1,20 @@
+package colors
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// notices is where status messages are printed. It's stdout, unless stdout is reserved for machine readable output
+var notices io.Writer = os.Stdout
+
+// SetNotices sends the status messages printed with Notice to w
+func SetNotices(w io.Writer) {
+	notices = w
+}
+
+// Notice prints a status message, such as one formatted by Status, to where status messages go
+func Notice(message string) {
+	fmt.Fprintln(notices, message)
+}
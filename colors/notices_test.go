@@ -0,0 +1,20 @@
+package colors
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestNotice(t *testing.T) {
+	var written bytes.Buffer
+
+	SetNotices(&written)
+	defer SetNotices(os.Stdout)
+
+	Notice("Creating change set...")
+
+	if written.String() != "Creating change set...\n" {
+		t.Errorf("expected the notice on its own line, got %q", written.String())
+	}
+}
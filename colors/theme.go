@@ -0,0 +1,186 @@
+package colors
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ansiCode matches the SGR parameters of an ANSI color, e.g. 31 or 1;31
+var ansiCode = regexp.MustCompile(`^[0-9]+(;[0-9]+)*$`)
+
+// defaultTheme maps each semantic color to its ANSI code
+var defaultTheme = map[string]string{
+	"error":       "1;31",
+	"docs":        "1;35",
+	"status":      "1;36",
+	"success":     "1;32",
+	"pending":     "1;33",
+	"warning":     "1;33",
+	"replacement": "1;31",
+}
+
+// tviewColors names the tview color of each ANSI foreground code, for the table output
+var tviewColors = map[string]string{
+	"30": "black", "31": "red", "32": "green", "33": "yellow", "34": "blue", "35": "fuchsia", "36": "aqua", "37": "white",
+	"90": "gray", "91": "red", "92": "lime", "93": "yellow", "94": "blue", "95": "fuchsia", "96": "aqua", "97": "white",
+}
+
+// theme is the ANSI code of each semantic color, read each time a message is formatted
+var theme = copyTheme(defaultTheme)
+
+// ThemePath returns the theme file to load, CIRRUS_THEME if set or ~/.cirrus/theme.json otherwise
+func ThemePath() string {
+	if location, ok := os.LookupEnv("CIRRUS_THEME"); ok {
+		return location
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(home, ".cirrus", "theme.json")
+}
+
+// LoadTheme reads a JSON object of semantic color (error, docs, status, success, pending, warning, replacement) to ANSI code,
+// e.g. {"error": "1;31"}, and applies it over the default theme. A missing file keeps the defaults. An invalid file also keeps
+// the defaults, and is reported with every problem at once
+func LoadTheme(location string) error {
+	contents, err := ioutil.ReadFile(location)
+	if os.IsNotExist(err) || location == "" {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	overrides := make(map[string]string)
+	if err := json.Unmarshal(contents, &overrides); err != nil {
+		return fmt.Errorf("theme %s must be a JSON object of semantic color to ANSI code", location)
+	}
+
+	if problems := themeProblems(overrides); len(problems) > 0 {
+		return fmt.Errorf("theme %s is invalid, using the default colors:\n  %s", location, strings.Join(problems, "\n  "))
+	}
+
+	for role, code := range overrides {
+		theme[role] = code
+	}
+
+	refreshPrefixes()
+
+	return nil
+}
+
+// SetThemeColors applies semantic colors given as role=code, e.g. warning=1;35, over the theme, so flags override the theme file.
+// Nothing is applied unless every assignment is valid
+func SetThemeColors(assignments []string) error {
+	overrides := make(map[string]string)
+	problems := make([]string, 0)
+
+	for _, assignment := range assignments {
+		parts := strings.SplitN(assignment, "=", 2)
+		if len(parts) != 2 {
+			problems = append(problems, fmt.Sprintf("%s is not of the form role=code", assignment))
+			continue
+		}
+
+		overrides[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	problems = append(problems, themeProblems(overrides)...)
+
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return fmt.Errorf("invalid theme colors:\n  %s", strings.Join(problems, "\n  "))
+	}
+
+	for role, code := range overrides {
+		theme[role] = code
+	}
+
+	refreshPrefixes()
+
+	return nil
+}
+
+// themeProblems lists, sorted, the unknown semantic colors and invalid ANSI codes of a theme
+func themeProblems(overrides map[string]string) []string {
+	problems := make([]string, 0)
+
+	for role, code := range overrides {
+		if _, ok := defaultTheme[role]; !ok {
+			problems = append(problems, fmt.Sprintf("%s is not a semantic color. Expected %s", role, strings.Join(themeRoles(), ", ")))
+			continue
+		}
+
+		if !ansiCode.MatchString(code) {
+			problems = append(problems, fmt.Sprintf("%s of %s is not an ANSI code such as 1;31", code, role))
+		}
+	}
+
+	sort.Strings(problems)
+
+	return problems
+}
+
+// TviewColor returns the tview color name of a semantic color of the theme, from the last foreground code of its ANSI code.
+// Codes without a named foreground color, such as 256 color codes, fall back to the default theme
+func TviewColor(role string) string {
+	for _, code := range []string{theme[role], defaultTheme[role]} {
+		name := ""
+
+		for _, part := range strings.Split(code, ";") {
+			// extended 38;5;n and 38;2;r;g;b colors end the named ones, and their numbers aren't codes of their own
+			if part == "38" || part == "48" {
+				break
+			}
+
+			if named, ok := tviewColors[part]; ok {
+				name = named
+			}
+		}
+
+		if name != "" {
+			return name
+		}
+	}
+
+	return "white"
+}
+
+func themeRoles() []string {
+	roles := make([]string, 0)
+
+	for role := range defaultTheme {
+		roles = append(roles, role)
+	}
+
+	sort.Strings(roles)
+
+	return roles
+}
+
+func copyTheme(source map[string]string) map[string]string {
+	copied := make(map[string]string)
+
+	for role, code := range source {
+		copied[role] = code
+	}
+
+	return copied
+}
+
+// themed tints text with the ANSI code of a semantic color of the theme
+func themed(role string) func(...interface{}) string {
+	return func(args ...interface{}) string {
+		return color("\033[" + theme[role] + "m%s\033[0m")(args...)
+	}
+}
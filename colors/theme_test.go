@@ -0,0 +1,61 @@
+package colors
+
+import (
+	"testing"
+)
+
+// restoreTheme puts the theme back as it was once the test ends
+func restoreTheme(t *testing.T) {
+	previous := copyTheme(theme)
+
+	t.Cleanup(func() {
+		theme = previous
+		refreshPrefixes()
+	})
+}
+
+func TestSetThemeColors(t *testing.T) {
+	restoreTheme(t)
+
+	if err := SetThemeColors([]string{"warning=1;35", "replacement = 31"}); err != nil {
+		t.Fatalf("expected valid colors to apply, got %s", err)
+	}
+
+	if theme["warning"] != "1;35" || theme["replacement"] != "31" {
+		t.Errorf("expected the colors to be applied, got %v", theme)
+	}
+
+	err := SetThemeColors([]string{"error=1;34", "unknown=1;31", "status=blue", "success"})
+	if err == nil {
+		t.Fatalf("expected invalid colors to be rejected")
+	}
+
+	if theme["error"] != defaultTheme["error"] {
+		t.Errorf("expected nothing to be applied when any color is invalid, error is %s", theme["error"])
+	}
+}
+
+func TestTviewColor(t *testing.T) {
+	restoreTheme(t)
+
+	tests := []struct {
+		code     string
+		expected string
+	}{
+		{code: "1;31", expected: "red"},
+		{code: "33", expected: "yellow"},
+		{code: "1;4;92", expected: "lime"},
+		{code: "31;1;35", expected: "fuchsia"},
+		// extended colors have no tview name, so the default replacement color is used
+		{code: "38;5;31", expected: "red"},
+		{code: "1", expected: "red"},
+	}
+
+	for _, test := range tests {
+		theme["replacement"] = test.code
+
+		if got := TviewColor("replacement"); got != test.expected {
+			t.Errorf("expected %s to be %s, got %s", test.code, test.expected, got)
+		}
+	}
+}
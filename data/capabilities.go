@@ -0,0 +1,78 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/colors"
+)
+
+// KnownCapabilities are the capabilities a change set can be granted
+var KnownCapabilities = []cloudformation.Capability{
+	cloudformation.CapabilityCapabilityIam,
+	cloudformation.CapabilityCapabilityNamedIam,
+	cloudformation.CapabilityCapabilityAutoExpand,
+}
+
+// ParseCapabilities converts capability names, case insensitively, reporting every unknown name at once
+func ParseCapabilities(names []string) ([]cloudformation.Capability, error) {
+	known := make(map[string]cloudformation.Capability)
+	for _, capability := range KnownCapabilities {
+		known[string(capability)] = capability
+	}
+
+	capabilities := make([]cloudformation.Capability, 0)
+	unknown := make([]string, 0)
+
+	for _, name := range names {
+		capability, ok := known[strings.ToUpper(strings.TrimSpace(name))]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+
+		capabilities = append(capabilities, capability)
+	}
+
+	if len(unknown) > 0 {
+		docsMessage := "https://docs.aws.amazon.com/AWSCloudFormation/latest/APIReference/API_CreateChangeSet.html"
+		unknownCapabilities := fmt.Sprintf("Unknown capability %s. Expected %s", strings.Join(unknown, ", "), capabilityNames(KnownCapabilities))
+
+		return nil, errors.New(fmt.Sprintf("%s \n %s", colors.Error(unknownCapabilities), colors.Docs(docsMessage)))
+	}
+
+	return capabilities, nil
+}
+
+// MissingCapabilities returns the required capabilities that weren't granted. CAPABILITY_NAMED_IAM also covers CAPABILITY_IAM
+func MissingCapabilities(required []cloudformation.Capability, granted []cloudformation.Capability) []cloudformation.Capability {
+	has := make(map[cloudformation.Capability]bool)
+	for _, capability := range granted {
+		has[capability] = true
+	}
+
+	if has[cloudformation.CapabilityCapabilityNamedIam] {
+		has[cloudformation.CapabilityCapabilityIam] = true
+	}
+
+	missing := make([]cloudformation.Capability, 0)
+
+	for _, capability := range required {
+		if !has[capability] {
+			missing = append(missing, capability)
+		}
+	}
+
+	return missing
+}
+
+func capabilityNames(capabilities []cloudformation.Capability) string {
+	names := make([]string, 0, len(capabilities))
+	for _, capability := range capabilities {
+		names = append(names, string(capability))
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,39 @@
+package data
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// coerceValues rewrites the number and boolean values under valueKey of parameter or tag file entries as strings. JSON numbers
+// keep the text they are written with, so 1.0 becomes "1.0" and 1e3 becomes "1e3". YAML numbers are read by YAML first, so 1.0
+// becomes "1" and 1e3 becomes "1000". true and false become "true" and "false". Any other type is left for decoding to reject
+func coerceValues(entries []map[string]json.RawMessage, valueKey string) {
+	for _, entry := range entries {
+		for key, raw := range entry {
+			if !strings.EqualFold(key, valueKey) {
+				continue
+			}
+
+			if coerced, ok := coerceScalar(raw); ok {
+				entry[key] = coerced
+			}
+		}
+	}
+}
+
+func coerceScalar(raw json.RawMessage) (json.RawMessage, bool) {
+	text := strings.TrimSpace(string(raw))
+
+	if text == "true" || text == "false" {
+		return json.RawMessage(strconv.Quote(text)), true
+	}
+
+	var number json.Number
+	if err := json.Unmarshal([]byte(text), &number); err != nil || strings.HasPrefix(text, `"`) {
+		return raw, false
+	}
+
+	return json.RawMessage(strconv.Quote(text)), true
+}
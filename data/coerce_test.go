@@ -0,0 +1,64 @@
+package data
+
+import (
+	"testing"
+)
+
+func TestGetParametersCoercion(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		contents string
+		expected string
+		invalid  bool
+	}{
+		{name: "JSON string", file: "parameters.json", contents: `[{"ParameterKey": "Size", "ParameterValue": "1.0"}]`, expected: "1.0"},
+		{name: "JSON decimal", file: "parameters.json", contents: `[{"ParameterKey": "Size", "ParameterValue": 1.0}]`, expected: "1.0"},
+		{name: "JSON integer", file: "parameters.json", contents: `[{"ParameterKey": "Size", "ParameterValue": 3}]`, expected: "3"},
+		{name: "JSON exponent", file: "parameters.json", contents: `[{"ParameterKey": "Size", "ParameterValue": 1e3}]`, expected: "1e3"},
+		{name: "JSON true", file: "parameters.json", contents: `[{"ParameterKey": "Size", "ParameterValue": true}]`, expected: "true"},
+		{name: "JSON false", file: "parameters.json", contents: `[{"ParameterKey": "Size", "ParameterValue": false}]`, expected: "false"},
+		{name: "JSON null", file: "parameters.json", contents: `[{"ParameterKey": "Size", "ParameterValue": null}]`, invalid: true},
+		{name: "JSON list", file: "parameters.json", contents: `[{"ParameterKey": "Size", "ParameterValue": [1]}]`, invalid: true},
+		{name: "JSON object", file: "parameters.json", contents: `[{"ParameterKey": "Size", "ParameterValue": {"a": 1}}]`, invalid: true},
+		{name: "YAML string", file: "parameters.yaml", contents: "- ParameterKey: Size\n  ParameterValue: \"1.0\"\n", expected: "1.0"},
+		{name: "YAML decimal", file: "parameters.yaml", contents: "- ParameterKey: Size\n  ParameterValue: 1.0\n", expected: "1"},
+		{name: "YAML exponent", file: "parameters.yaml", contents: "- ParameterKey: Size\n  ParameterValue: 1e3\n", expected: "1000"},
+		{name: "YAML true", file: "parameters.yaml", contents: "- ParameterKey: Size\n  ParameterValue: true\n", expected: "true"},
+		{name: "YAML false", file: "parameters.yaml", contents: "- ParameterKey: Size\n  ParameterValue: false\n", expected: "false"},
+		{name: "YAML null", file: "parameters.yaml", contents: "- ParameterKey: Size\n  ParameterValue: ~\n", invalid: true},
+		{name: "YAML list", file: "parameters.yaml", contents: "- ParameterKey: Size\n  ParameterValue: [a, b]\n", invalid: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			parameters, err := GetParameters(writeTempFile(t, test.file, test.contents), true)
+
+			if test.invalid {
+				if err == nil {
+					t.Errorf("expected the value to be rejected, got %v", parameters)
+				}
+
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unable to load the parameters: %s", err)
+			}
+
+			if len(parameters) != 1 || parameters[0].ParameterValue == nil || *parameters[0].ParameterValue != test.expected {
+				t.Errorf("expected the value %q, got %v", test.expected, parameters)
+			}
+		})
+	}
+}
+
+func TestGetParametersRejectsNonStringsWithoutCoercion(t *testing.T) {
+	for _, value := range []string{"1.0", "1e3", "true", "null", "[1]"} {
+		location := writeTempFile(t, "parameters.json", `[{"ParameterKey": "Size", "ParameterValue": `+value+`}]`)
+
+		if parameters, err := GetParameters(location, false); err == nil {
+			t.Errorf("expected %s to be rejected without coercion, got %v", value, parameters)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package data
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// CheckParameters checks parameters against the template's declarations: required parameters without a default must be given,
+// given parameters must be declared, and values must satisfy the declared constraints. Every problem is returned, ordered by key.
+// Parameters using the previous value can't be checked and are only required to be declared
+func CheckParameters(template Template, parameters []cloudformation.Parameter) []string {
+	problems := make([]string, 0)
+	given := make(map[string]bool)
+
+	for _, parameter := range parameters {
+		if parameter.ParameterKey == nil {
+			continue
+		}
+
+		key := *parameter.ParameterKey
+		given[key] = true
+
+		declaration, ok := template.Parameters[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s is not declared by the template", key))
+			continue
+		}
+
+		if parameter.UsePreviousValue != nil && *parameter.UsePreviousValue {
+			continue
+		}
+
+		for _, problem := range checkParameterValue(declaration, parameterValue(parameter)) {
+			problems = append(problems, fmt.Sprintf("%s %s", key, problem))
+		}
+	}
+
+	for _, key := range GetTemplateParameterKeys(template) {
+		if !given[key] && template.Parameters[key].Default == nil {
+			problems = append(problems, fmt.Sprintf("%s is required, since the template gives it no default", key))
+		}
+	}
+
+	sort.Strings(problems)
+
+	return problems
+}
+
+// UnusedParameters returns the keys of the given parameters that the template doesn't declare, in order. CloudFormation
+// rejects them, and they're usually typos of declared keys
+func UnusedParameters(template Template, parameters []cloudformation.Parameter) []string {
+	unused := make([]string, 0)
+
+	for _, parameter := range parameters {
+		if parameter.ParameterKey == nil {
+			continue
+		}
+
+		if _, ok := template.Parameters[*parameter.ParameterKey]; !ok {
+			unused = append(unused, *parameter.ParameterKey)
+		}
+	}
+
+	sort.Strings(unused)
+
+	return unused
+}
+
+// RemoveParameters returns the parameters without those of the given keys
+func RemoveParameters(parameters []cloudformation.Parameter, keys []string) []cloudformation.Parameter {
+	removed := make(map[string]bool)
+	for _, key := range keys {
+		removed[key] = true
+	}
+
+	kept := make([]cloudformation.Parameter, 0, len(parameters))
+
+	for _, parameter := range parameters {
+		if parameter.ParameterKey != nil && removed[*parameter.ParameterKey] {
+			continue
+		}
+
+		kept = append(kept, parameter)
+	}
+
+	return kept
+}
+
+// checkParameterValue checks a value against the constraints of its declaration. Constraints that don't apply to the
+// parameter's type are skipped, as CloudFormation ignores them
+func checkParameterValue(declaration TemplateParameter, value string) []string {
+	problems := make([]string, 0)
+
+	if len(declaration.AllowedValues) > 0 && !isAllowedValue(declaration.AllowedValues, value) {
+		problems = append(problems, fmt.Sprintf("must be one of %v", declaration.AllowedValues))
+	}
+
+	switch declaration.Type {
+	case "Number":
+		number, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return append(problems, "must be a number")
+		}
+
+		if min, ok := constraintNumber(declaration.MinValue); ok && number < min {
+			problems = append(problems, fmt.Sprintf("must be at least %v", declaration.MinValue))
+		}
+
+		if max, ok := constraintNumber(declaration.MaxValue); ok && number > max {
+			problems = append(problems, fmt.Sprintf("must be at most %v", declaration.MaxValue))
+		}
+	case "String":
+		if min, ok := constraintNumber(declaration.MinLength); ok && float64(len(value)) < min {
+			problems = append(problems, fmt.Sprintf("must be at least %v characters", declaration.MinLength))
+		}
+
+		if max, ok := constraintNumber(declaration.MaxLength); ok && float64(len(value)) > max {
+			problems = append(problems, fmt.Sprintf("must be at most %v characters", declaration.MaxLength))
+		}
+
+		if declaration.AllowedPattern != "" {
+			// CloudFormation matches the whole value against the pattern
+			pattern, err := regexp.Compile("^(?:" + declaration.AllowedPattern + ")$")
+			if err == nil && !pattern.MatchString(value) {
+				problems = append(problems, fmt.Sprintf("must match the pattern %s", declaration.AllowedPattern))
+			}
+		}
+	}
+
+	return problems
+}
+
+func isAllowedValue(allowed []interface{}, value string) bool {
+	for _, candidate := range allowed {
+		if fmt.Sprint(candidate) == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// constraintNumber reads a numeric constraint, which templates may give as a number or a string
+func constraintNumber(constraint interface{}) (float64, bool) {
+	if constraint == nil {
+		return 0, false
+	}
+
+	number, err := strconv.ParseFloat(fmt.Sprint(constraint), 64)
+
+	return number, err == nil
+}
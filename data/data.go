@@ -6,6 +6,8 @@ import (
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
@@ -14,15 +16,21 @@ import (
 
 //DisplayRow is a normalized data structure to store change/event data to display
 type DisplayRow struct {
-	LogicalResourceID string
-	ResourceType      string
-	Status            cloudformation.ResourceStatus
-	Timestamp         time.Time
-	StatusReason      string
-	Replacement       cloudformation.Replacement
-	Action            cloudformation.ChangeAction
-	Source            DisplayRowSource
-	Active            bool
+	LogicalResourceID string                        `json:"logicalResourceId"`
+	ResourceType      string                        `json:"resourceType"`
+	Status            cloudformation.ResourceStatus `json:"status"`
+	Timestamp         time.Time                     `json:"timestamp"`
+	StartTimestamp    time.Time                     `json:"startTimestamp"`
+	StatusReason      string                        `json:"statusReason"`
+	Replacement       cloudformation.Replacement    `json:"replacement"`
+	Action            cloudformation.ChangeAction   `json:"action"`
+	Details           []ChangeDetail                `json:"details,omitempty"`
+	ReplacedBy        []string                      `json:"replacedBy,omitempty"`
+	Source            DisplayRowSource              `json:"source"`
+	Active            bool                          `json:"active"`
+	DeletionPolicy    string                        `json:"deletionPolicy,omitempty"`
+	DeletionStep      int                           `json:"deletionStep,omitempty"`
+	Parent            string                        `json:"parent,omitempty"`
 }
 
 //StackInfo is a normalized data structure to store identifier properties of a stack/change set
@@ -44,6 +52,36 @@ const (
 
 	//CloudformationStackResource is the string that represents a CloudFormation stack in a template
 	CloudformationStackResource string = "AWS::CloudFormation::Stack"
+
+	//StackIDPrefix is the prefix every CloudFormation stack ID (ARN) starts with
+	StackIDPrefix string = "arn:aws:cloudformation:"
+)
+
+// resource rollback statuses that are newer than the SDK
+const (
+	//ResourceStatusRollbackInProgress indicates a resource is being rolled back after a failed create
+	ResourceStatusRollbackInProgress cloudformation.ResourceStatus = "ROLLBACK_IN_PROGRESS"
+
+	//ResourceStatusRollbackComplete indicates a resource was rolled back after a failed create
+	ResourceStatusRollbackComplete cloudformation.ResourceStatus = "ROLLBACK_COMPLETE"
+
+	//ResourceStatusRollbackFailed indicates a resource failed to roll back after a failed create
+	ResourceStatusRollbackFailed cloudformation.ResourceStatus = "ROLLBACK_FAILED"
+
+	//ResourceStatusUpdateRollbackInProgress indicates a resource is being rolled back after a failed update
+	ResourceStatusUpdateRollbackInProgress cloudformation.ResourceStatus = "UPDATE_ROLLBACK_IN_PROGRESS"
+
+	//ResourceStatusUpdateRollbackComplete indicates a resource was rolled back after a failed update
+	ResourceStatusUpdateRollbackComplete cloudformation.ResourceStatus = "UPDATE_ROLLBACK_COMPLETE"
+
+	//ResourceStatusUpdateRollbackFailed indicates a resource failed to roll back after a failed update
+	ResourceStatusUpdateRollbackFailed cloudformation.ResourceStatus = "UPDATE_ROLLBACK_FAILED"
+)
+
+// stack statuses that are newer than the SDK
+const (
+	//StackStatusUpdateFailed indicates an update failed with rollback disabled, leaving the stack as the update left it
+	StackStatusUpdateFailed cloudformation.StackStatus = "UPDATE_FAILED"
 )
 
 var (
@@ -51,7 +89,12 @@ var (
 	PositiveEventStatus []cloudformation.ResourceStatus = []cloudformation.ResourceStatus{
 		cloudformation.ResourceStatusCreateComplete,
 		cloudformation.ResourceStatusDeleteComplete,
+		cloudformation.ResourceStatusDeleteSkipped,
 		cloudformation.ResourceStatusUpdateComplete,
+		cloudformation.ResourceStatusImportComplete,
+		cloudformation.ResourceStatusImportRollbackComplete,
+		ResourceStatusRollbackComplete,
+		ResourceStatusUpdateRollbackComplete,
 	}
 
 	//NegativeEventStatus indicates negative event statuses
@@ -59,6 +102,10 @@ var (
 		cloudformation.ResourceStatusCreateFailed,
 		cloudformation.ResourceStatusDeleteFailed,
 		cloudformation.ResourceStatusUpdateFailed,
+		cloudformation.ResourceStatusImportFailed,
+		cloudformation.ResourceStatusImportRollbackFailed,
+		ResourceStatusRollbackFailed,
+		ResourceStatusUpdateRollbackFailed,
 	}
 
 	//PendingEventStatus indicates an event status that is in a pending state
@@ -66,6 +113,10 @@ var (
 		cloudformation.ResourceStatusCreateInProgress,
 		cloudformation.ResourceStatusDeleteInProgress,
 		cloudformation.ResourceStatusUpdateInProgress,
+		cloudformation.ResourceStatusImportInProgress,
+		cloudformation.ResourceStatusImportRollbackInProgress,
+		ResourceStatusRollbackInProgress,
+		ResourceStatusUpdateRollbackInProgress,
 	}
 
 	//PositiveStackStatus status indicates a stack is in a positive terminal state
@@ -80,6 +131,7 @@ var (
 	NegativeStackStatus []cloudformation.StackStatus = []cloudformation.StackStatus{
 		cloudformation.StackStatusCreateFailed,
 		cloudformation.StackStatusDeleteFailed,
+		StackStatusUpdateFailed,
 		cloudformation.StackStatusUpdateRollbackComplete,
 		cloudformation.StackStatusUpdateRollbackFailed,
 		cloudformation.StackStatusRollbackFailed,
@@ -108,12 +160,27 @@ func ChangeMap(changes []cloudformation.Change, active bool) map[string]DisplayR
 	mapChanges := make(map[string]DisplayRow)
 
 	for _, change := range changes {
+		if !IsResourceChange(change) {
+			continue
+		}
+
 		mapChanges[*change.ResourceChange.LogicalResourceId] = CreateDisplayRowFromChange(change, active)
 	}
 
 	return mapChanges
 }
 
+//IsResourceChange determines if a change describes a resource. Other change types may not carry a ResourceChange and can't be displayed
+func IsResourceChange(change cloudformation.Change) bool {
+	if change.Type != "" && change.Type != cloudformation.ChangeTypeResource {
+		return false
+	}
+
+	resource := change.ResourceChange
+
+	return resource != nil && resource.LogicalResourceId != nil && resource.ResourceType != nil
+}
+
 //CreateDisplayRowFromChange normalizes a cloudformation change into a display row
 func CreateDisplayRowFromChange(change cloudformation.Change, active bool) DisplayRow {
 	return DisplayRow{
@@ -121,6 +188,8 @@ func CreateDisplayRowFromChange(change cloudformation.Change, active bool) Displ
 		ResourceType:      *change.ResourceChange.ResourceType,
 		Replacement:       change.ResourceChange.Replacement,
 		Action:            change.ResourceChange.Action,
+		Details:           ChangeDetails(change),
+		ReplacedBy:        ReplacementCausingProperties(change),
 		Source:            DisplayRowSourceChangeSet,
 		Active:            active,
 	}
@@ -137,15 +206,49 @@ func EventMap(events []cloudformation.StackEvent) map[string]DisplayRow {
 	return mapEvents
 }
 
-//CreateDisplayRowFromEvent normalizes a cloudformation event into a display row
+// EventList normalizes a slice of events into DisplayRows, one per event and oldest first, keeping every event of a resource
+// where EventMap keeps only its latest
+func EventList(events []cloudformation.StackEvent) []DisplayRow {
+	rows := make([]DisplayRow, 0, len(events))
+
+	for _, event := range events {
+		rows = append(rows, CreateDisplayRowFromEvent(event))
+	}
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].Timestamp.Before(rows[j].Timestamp)
+	})
+
+	return rows
+}
+
+//CreateDisplayRowFromEvent normalizes a cloudformation event into a display row, with its status reason redacted
 func CreateDisplayRowFromEvent(event cloudformation.StackEvent) DisplayRow {
-	return DisplayRow{
+	row := DisplayRow{
 		LogicalResourceID: *event.LogicalResourceId,
 		ResourceType:      *event.ResourceType,
 		Status:            event.ResourceStatus,
 		Timestamp:         *event.Timestamp,
+		StartTimestamp:    *event.Timestamp,
 		Source:            DisplayRowSourceEvent,
 	}
+
+	if event.ResourceStatusReason != nil {
+		row.StatusReason = Redact(*event.ResourceStatusReason)
+	}
+
+	return row
+}
+
+//MergeEventRow creates a display row from an event, keeping the start time of the resource's previous event row
+func MergeEventRow(previous DisplayRow, event cloudformation.StackEvent) DisplayRow {
+	row := CreateDisplayRowFromEvent(event)
+
+	if previous.Source == DisplayRowSourceEvent && !previous.StartTimestamp.IsZero() {
+		row.StartTimestamp = previous.StartTimestamp
+	}
+
+	return row
 }
 
 //ResourceMap normalizes a slice of resource summaries into a map of DisplayRows
@@ -180,20 +283,27 @@ func ActivateDisplayRows(displayRows map[string]DisplayRow) map[string]DisplayRo
 	return activatedDisplayRows
 }
 
-//GetResourcesFromPaginator takes a ListStackResourcesPaginator and returns a list of StackResourceSummaries
-func GetResourcesFromPaginator(paginator *cloudformation.ListStackResourcesPaginator) []cloudformation.StackResourceSummary {
+//IsStackID determines if the given stack identifier is a full stack ID (ARN) rather than a stack name
+func IsStackID(stack string) bool {
+	return strings.HasPrefix(stack, StackIDPrefix)
+}
+
+//GetResourcesFromPaginator takes a ListStackResourcesPaginator and returns a list of StackResourceSummaries, paging with the given context
+func GetResourcesFromPaginator(ctx context.Context, paginator *cloudformation.ListStackResourcesPaginator) []cloudformation.StackResourceSummary {
 	resources := make([]cloudformation.StackResourceSummary, 0)
 
-	for paginator.Next(context.TODO()) {
+	for paginator.Next(ctx) {
 		resources = append(resources, paginator.CurrentPage().StackResourceSummaries...)
 	}
 
 	return resources
 }
 
-// GetTags gets the tags from the location provided. If tags don't exist, return an empty tag slice
-func GetTags(location string) ([]cloudformation.Tag, error) {
-	invalidJSON := "Unable to load tags. tags must be valid JSON and only of type string"
+// GetTags gets the tags from the JSON or YAML file at the location provided. If tags don't exist, or no location is given, return
+// an empty tag slice, unless similarly named files suggest a typo. With coerce, number and boolean values are accepted and
+// converted to strings, as for GetParameters
+func GetTags(location string, coerce bool) ([]cloudformation.Tag, error) {
+	invalidJSON := "Unable to load tags. Tags must be valid JSON or YAML and only of type string, or also numbers and booleans with --strict-strings=false"
 	docsMessage := "https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/aws-properties-resource-tags.html"
 	errorMessage := fmt.Sprintf("%s \n %s", colors.Error(invalidJSON), colors.Docs(docsMessage))
 
@@ -201,19 +311,45 @@ func GetTags(location string) ([]cloudformation.Tag, error) {
 
 	tags, err := ioutil.ReadFile(location)
 	if err != nil {
-		return container, nil
+		return container, missingFileError("tags", location, err)
 	}
 
-	if err := json.Unmarshal(tags, &container); err != nil {
+	if err := unmarshalEntries(location, tags, "Value", coerce, &container); err != nil {
 		return nil, errors.New(errorMessage)
 	}
 
-	return container, nil
+	return container, ValidateTags(container)
+}
+
+// MarshalParameters renders parameters as indented JSON in the same shape as a parameters file
+func MarshalParameters(parameters []cloudformation.Parameter) ([]byte, error) {
+	entries := make([]map[string]interface{}, 0)
+
+	for _, parameter := range parameters {
+		entry := make(map[string]interface{})
+
+		if parameter.ParameterKey != nil {
+			entry["ParameterKey"] = *parameter.ParameterKey
+		}
+
+		if parameter.ParameterValue != nil {
+			entry["ParameterValue"] = *parameter.ParameterValue
+		}
+
+		if parameter.UsePreviousValue != nil {
+			entry["UsePreviousValue"] = *parameter.UsePreviousValue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return json.MarshalIndent(entries, "", "  ")
 }
 
-// GetParameters gets the tags from the location provided. If tags don't exist, return an empty tag slice
-func GetParameters(location string) ([]cloudformation.Parameter, error) {
-	invalidJSON := "Unable to load parameters. Parameters must be valid JSON and only of type string"
+// GetParameters gets the parameters from the JSON or YAML file at the location provided. If parameters don't exist, or no location
+// is given, return an empty parameter slice, unless similarly named files suggest a typo. With coerce, number and boolean values are accepted and converted to strings, as described by coerceValues
+func GetParameters(location string, coerce bool) ([]cloudformation.Parameter, error) {
+	invalidJSON := "Unable to load parameters. Parameters must be valid JSON or YAML and only of type string, or also numbers and booleans with --strict-strings=false"
 	docsMessage := "https://aws.amazon.com/blogs/devops/passing-parameters-to-cloudformation-stacks-with-the-aws-cli-and-powershell/"
 	errorMessage := fmt.Sprintf("%s \n %s", colors.Error(invalidJSON), colors.Docs(docsMessage))
 
@@ -221,10 +357,10 @@ func GetParameters(location string) ([]cloudformation.Parameter, error) {
 
 	parameters, err := ioutil.ReadFile(location)
 	if err != nil {
-		return container, nil
+		return container, missingFileError("parameters", location, err)
 	}
 
-	if err := json.Unmarshal(parameters, &container); err != nil {
+	if err := unmarshalEntries(location, parameters, "ParameterValue", coerce, &container); err != nil {
 		return nil, errors.New(errorMessage)
 	}
 
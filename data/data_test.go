@@ -0,0 +1,106 @@
+package data
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/utils"
+)
+
+// writeTempFile writes contents to a file of the given name in a directory removed once the test ends, returning its path
+func writeTempFile(t *testing.T, name string, contents string) string {
+	dir, err := ioutil.TempDir("", "cirrus")
+	if err != nil {
+		t.Fatalf("unable to create a temporary directory: %s", err)
+	}
+
+	t.Cleanup(func() {
+		os.RemoveAll(dir)
+	})
+
+	location := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(location, []byte(contents), 0644); err != nil {
+		t.Fatalf("unable to write %s: %s", location, err)
+	}
+
+	return location
+}
+
+func TestChangeMapSkipsNonResourceChanges(t *testing.T) {
+	changes := []cloudformation.Change{
+		{
+			Type: cloudformation.ChangeTypeResource,
+			ResourceChange: &cloudformation.ResourceChange{
+				Action:            cloudformation.ChangeActionAdd,
+				LogicalResourceId: aws.String("Bucket"),
+				ResourceType:      aws.String("AWS::S3::Bucket"),
+			},
+		},
+		// hook invocations are listed as changes of their own type, without a resource change
+		{Type: cloudformation.ChangeType("HookInvocation")},
+		{
+			Type: cloudformation.ChangeType("HookInvocation"),
+			ResourceChange: &cloudformation.ResourceChange{
+				LogicalResourceId: aws.String("Hook"),
+				ResourceType:      aws.String("AWS::CloudFormation::Hook"),
+			},
+		},
+		// a resource change missing what identifies it can't be displayed either
+		{Type: cloudformation.ChangeTypeResource},
+	}
+
+	rows := ChangeMap(changes, false)
+
+	if len(rows) != 1 {
+		t.Fatalf("expected only the resource change to be mapped, got %v", rows)
+	}
+
+	if row, ok := rows["Bucket"]; !ok || row.Action != cloudformation.ChangeActionAdd {
+		t.Errorf("expected a row adding Bucket, got %v", rows)
+	}
+}
+
+func TestEveryResourceStatusIsClassified(t *testing.T) {
+	statuses := []cloudformation.ResourceStatus{
+		cloudformation.ResourceStatusCreateInProgress,
+		cloudformation.ResourceStatusCreateFailed,
+		cloudformation.ResourceStatusCreateComplete,
+		cloudformation.ResourceStatusDeleteInProgress,
+		cloudformation.ResourceStatusDeleteFailed,
+		cloudformation.ResourceStatusDeleteComplete,
+		cloudformation.ResourceStatusDeleteSkipped,
+		cloudformation.ResourceStatusUpdateInProgress,
+		cloudformation.ResourceStatusUpdateFailed,
+		cloudformation.ResourceStatusUpdateComplete,
+		cloudformation.ResourceStatusImportFailed,
+		cloudformation.ResourceStatusImportComplete,
+		cloudformation.ResourceStatusImportInProgress,
+		cloudformation.ResourceStatusImportRollbackInProgress,
+		cloudformation.ResourceStatusImportRollbackFailed,
+		cloudformation.ResourceStatusImportRollbackComplete,
+		ResourceStatusRollbackInProgress,
+		ResourceStatusRollbackComplete,
+		ResourceStatusRollbackFailed,
+		ResourceStatusUpdateRollbackInProgress,
+		ResourceStatusUpdateRollbackComplete,
+		ResourceStatusUpdateRollbackFailed,
+	}
+
+	for _, status := range statuses {
+		classes := 0
+
+		for _, classified := range [][]cloudformation.ResourceStatus{PositiveEventStatus, NegativeEventStatus, PendingEventStatus} {
+			if utils.ContainsResourceStatus(classified, status) {
+				classes++
+			}
+		}
+
+		if classes != 1 {
+			t.Errorf("expected %s to be classified exactly once, it's in %d classes", status, classes)
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package data
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// DeletionPolicyDelete deletes the resource with the stack, the default when a resource declares no policy
+	DeletionPolicyDelete string = "Delete"
+
+	// DeletionPolicyRetain keeps the resource in the account when the stack is deleted
+	DeletionPolicyRetain string = "Retain"
+
+	// DeletionPolicyRetainExceptOnCreate keeps the resource unless it is rolled back during the create that added it
+	DeletionPolicyRetainExceptOnCreate string = "RetainExceptOnCreate"
+
+	// DeletionPolicySnapshot snapshots the resource before deleting it
+	DeletionPolicySnapshot string = "Snapshot"
+)
+
+// subVariable matches the ${Name} and ${Name.Attribute} variables of Fn::Sub, but not the ${!Literal} escape
+var subVariable = regexp.MustCompile(`\$\{([^!}][^}.]*)`)
+
+// DeletionPolicies returns the DeletionPolicy of every resource in a template, Delete when it declares none. Policies given
+// by an intrinsic function can't be resolved without the stack's parameters, so they are left empty
+func DeletionPolicies(template Template) map[string]string {
+	policies := make(map[string]string)
+
+	for logicalID, resource := range template.Resources {
+		switch policy := resource.DeletionPolicy.(type) {
+		case nil:
+			policies[logicalID] = DeletionPolicyDelete
+		case string:
+			policies[logicalID] = policy
+		default:
+			policies[logicalID] = ""
+		}
+	}
+
+	return policies
+}
+
+// IsRetainedOnDelete determines if a deletion policy leaves the resource behind when the stack is deleted
+func IsRetainedOnDelete(policy string) bool {
+	return policy == DeletionPolicyRetain || policy == DeletionPolicyRetainExceptOnCreate
+}
+
+// ResourceDependencies returns the sorted resources each resource depends on, through DependsOn, Ref, Fn::GetAtt and Fn::Sub.
+// The template must use the long form of intrinsic functions, as cfn.NormalizeTemplate produces
+func ResourceDependencies(template Template) map[string][]string {
+	dependencies := make(map[string][]string)
+
+	for logicalID, resource := range template.Resources {
+		found := make(map[string]bool)
+
+		for _, dependency := range stringList(resource.DependsOn) {
+			found[dependency] = true
+		}
+
+		collectReferences(resource.Properties, found)
+
+		names := make([]string, 0)
+		for name := range found {
+			if _, ok := template.Resources[name]; ok && name != logicalID {
+				names = append(names, name)
+			}
+		}
+
+		sort.Strings(names)
+		dependencies[logicalID] = names
+	}
+
+	return dependencies
+}
+
+// DeletionOrder estimates the step each resource is deleted in. CloudFormation deletes a resource only after everything
+// depending on it, so resources nothing depends on go first, in step 1. Resources in the same step may be deleted in parallel.
+// It is a hint, as references hidden in conditions or custom resources aren't seen
+func DeletionOrder(template Template) map[string]int {
+	dependents := make(map[string][]string)
+
+	for logicalID, dependencies := range ResourceDependencies(template) {
+		for _, dependency := range dependencies {
+			dependents[dependency] = append(dependents[dependency], logicalID)
+		}
+	}
+
+	steps := make(map[string]int)
+	visiting := make(map[string]bool)
+
+	var step func(string) int
+	step = func(logicalID string) int {
+		if known, ok := steps[logicalID]; ok {
+			return known
+		}
+
+		// a dependency cycle can't deploy, but mustn't hang the preview either
+		if visiting[logicalID] {
+			return 0
+		}
+
+		visiting[logicalID] = true
+
+		latest := 0
+		for _, dependent := range dependents[logicalID] {
+			if dependentStep := step(dependent); dependentStep > latest {
+				latest = dependentStep
+			}
+		}
+
+		steps[logicalID] = latest + 1
+
+		return latest + 1
+	}
+
+	for logicalID := range template.Resources {
+		step(logicalID)
+	}
+
+	return steps
+}
+
+// AnnotateDeletions sets the deletion policy and deletion step of the rows of a stack being deleted from its template
+func AnnotateDeletions(displayRows map[string]DisplayRow, template Template) {
+	policies := DeletionPolicies(template)
+	steps := DeletionOrder(template)
+
+	for logicalID, row := range displayRows {
+		row.DeletionPolicy = policies[logicalID]
+		row.DeletionStep = steps[logicalID]
+		displayRows[logicalID] = row
+	}
+}
+
+// collectReferences records the targets of every Ref, Fn::GetAtt and Fn::Sub variable within a value
+func collectReferences(value interface{}, found map[string]bool) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typed {
+			switch key {
+			case "Ref":
+				if name, ok := child.(string); ok {
+					found[name] = true
+				}
+			case "Fn::GetAtt":
+				if attribute := stringList(child); len(attribute) > 0 {
+					found[strings.SplitN(attribute[0], ".", 2)[0]] = true
+				}
+			case "Fn::Sub":
+				collectSubReferences(child, found)
+				continue
+			}
+
+			collectReferences(child, found)
+		}
+	case []interface{}:
+		for _, child := range typed {
+			collectReferences(child, found)
+		}
+	}
+}
+
+// collectSubReferences records the variables of an Fn::Sub string that aren't defined by its own variable map
+func collectSubReferences(value interface{}, found map[string]bool) {
+	body := value
+	variables := map[string]interface{}{}
+
+	if list, ok := value.([]interface{}); ok && len(list) > 0 {
+		body = list[0]
+
+		if len(list) > 1 {
+			if defined, ok := list[1].(map[string]interface{}); ok {
+				variables = defined
+			}
+		}
+	}
+
+	if text, ok := body.(string); ok {
+		for _, match := range subVariable.FindAllStringSubmatch(text, -1) {
+			if _, defined := variables[match[1]]; !defined {
+				found[match[1]] = true
+			}
+		}
+	}
+
+	collectReferences(variables, found)
+}
+
+// stringList reads a value that may be a single string or a list of strings, such as DependsOn
+func stringList(value interface{}) []string {
+	switch typed := value.(type) {
+	case string:
+		return []string{typed}
+	case []interface{}:
+		list := make([]string, 0)
+
+		for _, item := range typed {
+			if text, ok := item.(string); ok {
+				list = append(list, text)
+			}
+		}
+
+		return list
+	}
+
+	return nil
+}
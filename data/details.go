@@ -0,0 +1,192 @@
+package data
+
+import (
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// ChangeDetail is a single reason a resource is modified by a change set
+type ChangeDetail struct {
+	// Attribute is the part of the resource being changed, such as Properties or Tags
+	Attribute string `json:"attribute"`
+
+	// Property is the name of the changed property, when Attribute is Properties
+	Property string `json:"property"`
+
+	// Source is what triggered the change, such as DirectModification or ParameterReference
+	Source cloudformation.ChangeSource `json:"source"`
+
+	// CausingEntity is the parameter, resource, or attribute that triggered the change, empty for direct modifications
+	CausingEntity string `json:"causingEntity"`
+
+	// Dynamic is true when the new value depends on an intrinsic function and can't be known before executing
+	Dynamic bool `json:"dynamic"`
+
+	RequiresRecreation cloudformation.RequiresRecreation `json:"requiresRecreation"`
+
+	// Before and After are the values of the property around the change, when described with property values
+	Before *string `json:"before,omitempty"`
+	After  *string `json:"after,omitempty"`
+}
+
+// PropertyValue is the value of a changed property before and after a change, as described with IncludePropertyValues
+type PropertyValue struct {
+	Attribute string
+	Name      string
+	Path      string
+	Before    *string
+	After     *string
+}
+
+// ApplyPropertyValues sets the before and after values of each row's change details from the values described for its resource,
+// which follow the same order. Values are redacted like any other output, and masked whole when a sensitive parameter changes them
+func ApplyPropertyValues(rows map[string]DisplayRow, values map[string][]PropertyValue) {
+	for logicalID, resourceValues := range values {
+		row, ok := rows[logicalID]
+		if !ok {
+			continue
+		}
+
+		for i, detail := range row.Details {
+			if i >= len(resourceValues) {
+				break
+			}
+
+			row.Details[i].Before = redactValue(detail, resourceValues[i].Before)
+			row.Details[i].After = redactValue(detail, resourceValues[i].After)
+		}
+
+		rows[logicalID] = row
+	}
+}
+
+func redactValue(detail ChangeDetail, value *string) *string {
+	if value == nil {
+		return nil
+	}
+
+	redacted := Redact(*value)
+	if detail.Source == cloudformation.ChangeSourceParameterReference {
+		redacted = RedactParameter(detail.CausingEntity, *value)
+	}
+
+	return &redacted
+}
+
+// HasValues determines if the detail was described with the values of its property
+func (detail ChangeDetail) HasValues() bool {
+	return detail.Before != nil || detail.After != nil
+}
+
+// DescribeChangeValues renders the values of a change detail as one line, e.g. "Properties.InstanceType: t3.micro → t3.large"
+func DescribeChangeValues(detail ChangeDetail) string {
+	return fmt.Sprintf("%s: %s → %s", detailTarget(detail), valueOrNone(detail.Before), valueOrNone(detail.After))
+}
+
+func valueOrNone(value *string) string {
+	if value == nil {
+		return "(none)"
+	}
+
+	return *value
+}
+
+func detailTarget(detail ChangeDetail) string {
+	if detail.Property != "" {
+		return detail.Attribute + "." + detail.Property
+	}
+
+	return detail.Attribute
+}
+
+// ChangeDetails extracts the readable details of a change set change
+func ChangeDetails(change cloudformation.Change) []ChangeDetail {
+	details := make([]ChangeDetail, 0)
+
+	if change.ResourceChange == nil {
+		return details
+	}
+
+	for _, detail := range change.ResourceChange.Details {
+		changeDetail := ChangeDetail{
+			Source:  detail.ChangeSource,
+			Dynamic: detail.Evaluation == cloudformation.EvaluationTypeDynamic,
+		}
+
+		if detail.CausingEntity != nil {
+			changeDetail.CausingEntity = *detail.CausingEntity
+		}
+
+		if detail.Target != nil {
+			changeDetail.Attribute = string(detail.Target.Attribute)
+			changeDetail.RequiresRecreation = detail.Target.RequiresRecreation
+
+			if detail.Target.Name != nil {
+				changeDetail.Property = *detail.Target.Name
+			}
+		}
+
+		details = append(details, changeDetail)
+	}
+
+	return details
+}
+
+// ReplacementCausingProperties lists, in change set order, the properties whose modification always or conditionally replaces the resource
+func ReplacementCausingProperties(change cloudformation.Change) []string {
+	properties := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, detail := range ChangeDetails(change) {
+		if detail.Property == "" || seen[detail.Property] {
+			continue
+		}
+
+		if detail.RequiresRecreation == cloudformation.RequiresRecreationAlways || detail.RequiresRecreation == cloudformation.RequiresRecreationConditionally {
+			properties = append(properties, detail.Property)
+			seen[detail.Property] = true
+		}
+	}
+
+	return properties
+}
+
+// ReplacedResources lists, in change set order, the resources a change set will or may replace, e.g. "Bucket (conditional)"
+func ReplacedResources(changes []cloudformation.Change) []string {
+	replaced := make([]string, 0)
+
+	for _, change := range changes {
+		if !IsResourceChange(change) {
+			continue
+		}
+
+		switch change.ResourceChange.Replacement {
+		case cloudformation.ReplacementTrue:
+			replaced = append(replaced, *change.ResourceChange.LogicalResourceId)
+		case cloudformation.ReplacementConditional:
+			replaced = append(replaced, *change.ResourceChange.LogicalResourceId+" (conditional)")
+		}
+	}
+
+	return replaced
+}
+
+// DescribeChangeDetail renders a change detail as one line, e.g. "Properties.InstanceType by ParameterReference InstanceType, recreation Always"
+func DescribeChangeDetail(detail ChangeDetail) string {
+	line := fmt.Sprintf("%s by %s", detailTarget(detail), detail.Source)
+
+	if detail.CausingEntity != "" {
+		line += " " + detail.CausingEntity
+	}
+
+	if detail.Dynamic {
+		line += ", value known after execution"
+	}
+
+	if detail.RequiresRecreation != "" && detail.RequiresRecreation != cloudformation.RequiresRecreationNever {
+		line += fmt.Sprintf(", recreation %s", detail.RequiresRecreation)
+	}
+
+	return line
+}
@@ -0,0 +1,90 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// propertyDetail is a change detail modifying a property, with the recreation it requires
+func propertyDetail(name string, recreation cloudformation.RequiresRecreation) cloudformation.ResourceChangeDetail {
+	return cloudformation.ResourceChangeDetail{
+		ChangeSource: cloudformation.ChangeSourceDirectModification,
+		Evaluation:   cloudformation.EvaluationTypeStatic,
+		Target: &cloudformation.ResourceTargetDefinition{
+			Attribute:          cloudformation.ResourceAttributeProperties,
+			Name:               aws.String(name),
+			RequiresRecreation: recreation,
+		},
+	}
+}
+
+func TestReplacementCausingProperties(t *testing.T) {
+	change := cloudformation.Change{
+		Type: cloudformation.ChangeTypeResource,
+		ResourceChange: &cloudformation.ResourceChange{
+			Action:            cloudformation.ChangeActionModify,
+			LogicalResourceId: aws.String("Database"),
+			ResourceType:      aws.String("AWS::RDS::DBInstance"),
+			Replacement:       cloudformation.ReplacementTrue,
+			Details: []cloudformation.ResourceChangeDetail{
+				propertyDetail("DBInstanceClass", cloudformation.RequiresRecreationNever),
+				propertyDetail("Engine", cloudformation.RequiresRecreationAlways),
+				propertyDetail("AvailabilityZone", cloudformation.RequiresRecreationConditionally),
+				// a property changed by both a parameter and directly is listed once
+				propertyDetail("Engine", cloudformation.RequiresRecreationAlways),
+				{
+					ChangeSource: cloudformation.ChangeSourceDirectModification,
+					Target: &cloudformation.ResourceTargetDefinition{
+						Attribute:          cloudformation.ResourceAttributeTags,
+						RequiresRecreation: cloudformation.RequiresRecreationNever,
+					},
+				},
+			},
+		},
+	}
+
+	expected := []string{"Engine", "AvailabilityZone"}
+	if got := ReplacementCausingProperties(change); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestReplacementCausingPropertiesWithoutResourceChange(t *testing.T) {
+	if got := ReplacementCausingProperties(cloudformation.Change{}); len(got) != 0 {
+		t.Errorf("expected no properties for a change without a resource change, got %v", got)
+	}
+}
+
+func TestApplyPropertyValuesMasksSensitiveParameters(t *testing.T) {
+	template := Template{Parameters: map[string]TemplateParameter{"DbPassword": {NoEcho: true}}}
+
+	RedactParameters(parameters("DbPassword", "pw2"), template, nil)
+	defer RedactParameters(nil, Template{}, nil)
+
+	rows := map[string]DisplayRow{
+		"Database": {Details: []ChangeDetail{
+			{Attribute: "Properties", Property: "MasterUserPassword", Source: cloudformation.ChangeSourceParameterReference, CausingEntity: "DbPassword"},
+			{Attribute: "Properties", Property: "Port", Source: cloudformation.ChangeSourceDirectModification},
+		}},
+	}
+
+	ApplyPropertyValues(rows, map[string][]PropertyValue{
+		"Database": {
+			{Name: "MasterUserPassword", Before: aws.String("pw1"), After: aws.String("pw2")},
+			{Name: "Port", Before: aws.String("3306"), After: aws.String("5432")},
+		},
+	})
+
+	password := rows["Database"].Details[0]
+	if *password.Before != maskedParameterValue || *password.After != maskedParameterValue {
+		t.Errorf("expected a property set from a sensitive parameter masked whatever its length, got %s", DescribeChangeValues(password))
+	}
+
+	port := rows["Database"].Details[1]
+	if *port.Before != "3306" || *port.After != "5432" {
+		t.Errorf("expected other properties in the clear, got %s", DescribeChangeValues(port))
+	}
+}
@@ -0,0 +1,197 @@
+package data
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// ParameterDiff describes a parameter whose value differs between the deployed stack and the local parameters
+type ParameterDiff struct {
+	Key      string
+	Deployed string
+	Local    string
+}
+
+// ChangeScope describes which parts of a stack a deploy changes
+type ChangeScope string
+
+const (
+	// ChangeScopeNone indicates neither the template nor the parameters changed
+	ChangeScopeNone ChangeScope = "none"
+
+	// ChangeScopeTemplate indicates only the template changed
+	ChangeScopeTemplate ChangeScope = "template"
+
+	// ChangeScopeParameters indicates only the parameters changed
+	ChangeScopeParameters ChangeScope = "parameters"
+
+	// ChangeScopeBoth indicates both the template and the parameters changed
+	ChangeScopeBoth ChangeScope = "both"
+
+	// maskedParameterValue is the value CloudFormation returns for NoEcho parameters
+	maskedParameterValue string = "****"
+)
+
+// noChangesReasons are the status reasons CloudFormation gives a change set that has nothing to change
+var noChangesReasons = []string{
+	"The submitted information didn't contain changes",
+	"No updates are to be performed",
+}
+
+// IsNoChangesReason determines if a failed change set's status reason means there was nothing to change, rather than a real error
+func IsNoChangesReason(reason string) bool {
+	for _, noChanges := range noChangesReasons {
+		if strings.Contains(reason, noChanges) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DiffRowMaps compares two display row maps and returns the sorted logical IDs of rows that were added, removed, or changed
+func DiffRowMaps(previous map[string]DisplayRow, current map[string]DisplayRow) []string {
+	changed := make([]string, 0)
+
+	for logicalID, row := range current {
+		if prev, ok := previous[logicalID]; !ok || !reflect.DeepEqual(prev, row) {
+			changed = append(changed, logicalID)
+		}
+	}
+
+	for logicalID := range previous {
+		if _, ok := current[logicalID]; !ok {
+			changed = append(changed, logicalID)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
+// CopyDisplayRows returns a shallow copy of a display row map so later mutations don't affect it
+func CopyDisplayRows(displayRows map[string]DisplayRow) map[string]DisplayRow {
+	copied := make(map[string]DisplayRow)
+
+	for logicalID, row := range displayRows {
+		copied[logicalID] = row
+	}
+
+	return copied
+}
+
+// DiffParameters compares the local parameters against the deployed parameters and returns the differences sorted by key.
+// Only locally supplied keys are compared, since omitted keys fall back to template defaults. Parameters using the previous value
+// and masked NoEcho values can't be compared, so they are skipped
+func DiffParameters(deployed []cloudformation.Parameter, local []cloudformation.Parameter) []ParameterDiff {
+	diffs := make([]ParameterDiff, 0)
+
+	deployedValues := ParameterValues(deployed)
+
+	for _, parameter := range local {
+		if parameter.ParameterKey == nil || (parameter.UsePreviousValue != nil && *parameter.UsePreviousValue) {
+			continue
+		}
+
+		key := *parameter.ParameterKey
+		localValue := parameterValue(parameter)
+		deployedValue, ok := deployedValues[key]
+
+		if deployedValue == maskedParameterValue || (ok && localValue == deployedValue) {
+			continue
+		}
+
+		diffs = append(diffs, ParameterDiff{Key: key, Deployed: deployedValue, Local: localValue})
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Key < diffs[j].Key
+	})
+
+	return diffs
+}
+
+// UncomparedParameters lists, sorted, the locally supplied keys DiffParameters skips because the deployed value is a masked NoEcho value
+func UncomparedParameters(deployed []cloudformation.Parameter, local []cloudformation.Parameter) []string {
+	keys := make([]string, 0)
+
+	deployedValues := ParameterValues(deployed)
+
+	for _, parameter := range local {
+		if parameter.ParameterKey == nil || (parameter.UsePreviousValue != nil && *parameter.UsePreviousValue) {
+			continue
+		}
+
+		if deployedValues[*parameter.ParameterKey] == maskedParameterValue {
+			keys = append(keys, *parameter.ParameterKey)
+		}
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// DefaultToPreviousValues adds UsePreviousValue for every deployed parameter that has no local value, so it keeps its deployed value.
+// Parameters the template no longer declares are left out, since CloudFormation rejects them
+func DefaultToPreviousValues(local []cloudformation.Parameter, deployed []cloudformation.Parameter, templateKeys []string) []cloudformation.Parameter {
+	declared := make(map[string]bool)
+	for _, key := range templateKeys {
+		declared[key] = true
+	}
+
+	previous := make([]cloudformation.Parameter, 0)
+
+	for _, parameter := range deployed {
+		if parameter.ParameterKey == nil || !declared[*parameter.ParameterKey] {
+			continue
+		}
+
+		previous = append(previous, cloudformation.Parameter{
+			ParameterKey:     parameter.ParameterKey,
+			UsePreviousValue: aws.Bool(true),
+		})
+	}
+
+	return MergeParameters(previous, local)
+}
+
+// ParameterValues converts a slice of parameters into a map of parameter key to value
+func ParameterValues(parameters []cloudformation.Parameter) map[string]string {
+	values := make(map[string]string)
+
+	for _, parameter := range parameters {
+		if parameter.ParameterKey != nil {
+			values[*parameter.ParameterKey] = parameterValue(parameter)
+		}
+	}
+
+	return values
+}
+
+// DetermineChangeScope maps whether the template and parameters changed to a ChangeScope
+func DetermineChangeScope(templateChanged bool, parametersChanged bool) ChangeScope {
+	switch {
+	case templateChanged && parametersChanged:
+		return ChangeScopeBoth
+	case templateChanged:
+		return ChangeScopeTemplate
+	case parametersChanged:
+		return ChangeScopeParameters
+	}
+
+	return ChangeScopeNone
+}
+
+func parameterValue(parameter cloudformation.Parameter) string {
+	if parameter.ParameterValue == nil {
+		return ""
+	}
+
+	return *parameter.ParameterValue
+}
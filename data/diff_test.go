@@ -0,0 +1,35 @@
+package data
+
+import (
+	"testing"
+)
+
+func TestDefaultToPreviousValues(t *testing.T) {
+	deployed := parameters("Environment", "production", "Size", "large", "Removed", "value")
+	local := parameters("Size", "small")
+
+	merged := DefaultToPreviousValues(local, deployed, []string{"Environment", "Size", "Added"})
+
+	byKey := make(map[string]int)
+	for i, parameter := range merged {
+		byKey[*parameter.ParameterKey] = i
+	}
+
+	if len(merged) != 2 {
+		t.Fatalf("expected Environment and Size, got %v", parameterKeys(merged))
+	}
+
+	environment := merged[byKey["Environment"]]
+	if environment.UsePreviousValue == nil || !*environment.UsePreviousValue || environment.ParameterValue != nil {
+		t.Errorf("expected Environment to keep its deployed value, got %+v", environment)
+	}
+
+	size := merged[byKey["Size"]]
+	if size.UsePreviousValue != nil || size.ParameterValue == nil || *size.ParameterValue != "small" {
+		t.Errorf("expected the local Size to override the deployed one, got %+v", size)
+	}
+
+	if _, ok := byKey["Removed"]; ok {
+		t.Errorf("expected a parameter the template no longer declares to be left out")
+	}
+}
@@ -0,0 +1,79 @@
+package data
+
+import (
+	"sort"
+)
+
+// ImportIdentifierProperties maps the resource types import discovery supports to the template property naming the
+// resource, which is also the identifier CloudFormation imports it by
+var ImportIdentifierProperties = map[string]string{
+	"AWS::DynamoDB::Table": "TableName",
+	"AWS::Logs::LogGroup":  "LogGroupName",
+	"AWS::S3::Bucket":      "BucketName",
+}
+
+// ImportCandidate is a template resource that may already exist outside the stack
+type ImportCandidate struct {
+	LogicalResourceID string
+	ResourceType      string
+	IdentifierKey     string
+	Identifier        string
+}
+
+// ResourceToImport is an entry of a resources-to-import file, in the shape CloudFormation expects
+type ResourceToImport struct {
+	ResourceType       string            `json:"ResourceType"`
+	LogicalResourceID  string            `json:"LogicalResourceId"`
+	ResourceIdentifier map[string]string `json:"ResourceIdentifier"`
+}
+
+// FindImportCandidates lists, sorted by logical ID, the template resources outside the stack that discovery can check.
+// A resource can only be checked when its type is supported and it is named with a literal string. The logical IDs
+// of the remaining resources outside the stack are returned as unchecked
+func FindImportCandidates(template Template, managed map[string]bool) ([]ImportCandidate, []string) {
+	candidates := make([]ImportCandidate, 0)
+	unchecked := make([]string, 0)
+
+	for logicalID, resource := range template.Resources {
+		if managed[logicalID] {
+			continue
+		}
+
+		key, supported := ImportIdentifierProperties[resource.Type]
+		name, literal := resource.Properties[key].(string)
+
+		if !supported || !literal || name == "" {
+			unchecked = append(unchecked, logicalID)
+			continue
+		}
+
+		candidates = append(candidates, ImportCandidate{
+			LogicalResourceID: logicalID,
+			ResourceType:      resource.Type,
+			IdentifierKey:     key,
+			Identifier:        name,
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].LogicalResourceID < candidates[j].LogicalResourceID
+	})
+	sort.Strings(unchecked)
+
+	return candidates, unchecked
+}
+
+// ResourcesToImport converts the candidates found to exist into a skeleton resources-to-import file
+func ResourcesToImport(found []ImportCandidate) []ResourceToImport {
+	resources := make([]ResourceToImport, 0)
+
+	for _, candidate := range found {
+		resources = append(resources, ResourceToImport{
+			ResourceType:       candidate.ResourceType,
+			LogicalResourceID:  candidate.LogicalResourceID,
+			ResourceIdentifier: map[string]string{candidate.IdentifierKey: candidate.Identifier},
+		})
+	}
+
+	return resources
+}
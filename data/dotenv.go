@@ -0,0 +1,107 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/colors"
+)
+
+var dotenvKey = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*$`)
+
+// ParametersFromEnvFile reads a dotenv file of KEY=VALUE lines as parameters. Blank lines, # comments and an export prefix are
+// ignored, and values may be single or double quoted. Every malformed line is reported at once, without its value, since
+// these files usually hold secrets
+func ParametersFromEnvFile(location string) ([]cloudformation.Parameter, error) {
+	contents, err := ioutil.ReadFile(location)
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := make([]cloudformation.Parameter, 0)
+	problems := make([]string, 0)
+
+	for i, line := range strings.Split(strings.ReplaceAll(string(contents), "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(line, "export "), "=", 2)
+		key := strings.TrimSpace(parts[0])
+
+		if len(parts) != 2 || !dotenvKey.MatchString(key) {
+			problems = append(problems, fmt.Sprintf("line %d is not of the form KEY=VALUE", i+1))
+			continue
+		}
+
+		value, ok := parseEnvValue(strings.TrimSpace(parts[1]))
+		if !ok {
+			problems = append(problems, fmt.Sprintf("line %d has an unterminated quoted value for %s", i+1, key))
+			continue
+		}
+
+		parameters = append(parameters, cloudformation.Parameter{
+			ParameterKey:   aws.String(key),
+			ParameterValue: aws.String(value),
+		})
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New(colors.Error(fmt.Sprintf("Unable to load parameters from %s:\n  %s", location, strings.Join(problems, "\n  "))))
+	}
+
+	return parameters, nil
+}
+
+// parseEnvValue unquotes a dotenv value. Double quoted values support \n, \" and \\ escapes, single quoted values are literal,
+// and unquoted values end at an inline # comment
+func parseEnvValue(raw string) (string, bool) {
+	if raw == "" {
+		return "", true
+	}
+
+	quote := raw[0]
+
+	if quote != '"' && quote != '\'' {
+		if comment := strings.Index(raw, " #"); comment >= 0 {
+			raw = raw[:comment]
+		}
+
+		return strings.TrimSpace(raw), true
+	}
+
+	var value strings.Builder
+
+	for i := 1; i < len(raw); i++ {
+		char := raw[i]
+
+		if char == quote {
+			rest := strings.TrimSpace(raw[i+1:])
+			return value.String(), rest == "" || strings.HasPrefix(rest, "#")
+		}
+
+		if quote == '"' && char == '\\' && i+1 < len(raw) {
+			i++
+			switch raw[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			default:
+				value.WriteByte(raw[i])
+			}
+
+			continue
+		}
+
+		value.WriteByte(char)
+	}
+
+	return "", false
+}
@@ -0,0 +1,70 @@
+package data
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParametersFromEnvFile(t *testing.T) {
+	location := writeTempFile(t, ".env", strings.Join([]string{
+		"# deploy parameters",
+		"",
+		"Environment=production",
+		"export Region=eu-west-1",
+		`DoubleQuoted="hello # not a comment"`,
+		`SingleQuoted='literal \n value'`,
+		`Escaped="line\nbreak \"quoted\""`,
+		"Unquoted=value # trailing comment",
+		`QuotedWithComment="value" # trailing comment`,
+		"Empty=",
+		"  Padded  =  spaced  ",
+		"   # indented comment",
+	}, "\r\n"))
+
+	parameters, err := ParametersFromEnvFile(location)
+	if err != nil {
+		t.Fatalf("unable to read the env file: %s", err)
+	}
+
+	expected := map[string]string{
+		"Environment":       "production",
+		"Region":            "eu-west-1",
+		"DoubleQuoted":      "hello # not a comment",
+		"SingleQuoted":      `literal \n value`,
+		"Escaped":           "line\nbreak \"quoted\"",
+		"Unquoted":          "value",
+		"QuotedWithComment": "value",
+		"Empty":             "",
+		"Padded":            "spaced",
+	}
+
+	if got := ParameterValues(parameters); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+}
+
+func TestParametersFromEnvFileReportsMalformedLines(t *testing.T) {
+	location := writeTempFile(t, ".env", strings.Join([]string{
+		"Valid=value",
+		"no separator",
+		`Unterminated="secret value`,
+		`Trailing="value" garbage`,
+		"1Invalid=value",
+	}, "\n"))
+
+	_, err := ParametersFromEnvFile(location)
+	if err == nil {
+		t.Fatalf("expected malformed lines to be rejected")
+	}
+
+	for _, problem := range []string{"line 2 is not", "line 3 has an unterminated quoted value for Unterminated", "line 4 has", "line 5 is not"} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("expected %q to be reported, got %s", problem, err)
+		}
+	}
+
+	if strings.Contains(err.Error(), "secret value") {
+		t.Errorf("expected values to be left out of the error, got %s", err)
+	}
+}
@@ -0,0 +1,62 @@
+package data
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+var (
+	//cancellationReasons are failure reasons CloudFormation gives resources that failed only because another resource failed first
+	cancellationReasons []string = []string{
+		"Resource creation cancelled",
+		"Resource update cancelled",
+	}
+)
+
+// DeleteFailedResourceIDs lists, sorted, the logical IDs of resources of the stack itself whose last event is DELETE_FAILED.
+// Resources of nested stacks can only be retained by deleting their nested stack
+func DeleteFailedResourceIDs(rows map[string]DisplayRow) []string {
+	failed := make([]string, 0)
+
+	for logicalID, row := range rows {
+		if row.Source == DisplayRowSourceEvent && row.Parent == "" && row.Status == cloudformation.ResourceStatusDeleteFailed {
+			failed = append(failed, logicalID)
+		}
+	}
+
+	sort.Strings(failed)
+
+	return failed
+}
+
+// RootCause picks the failure that most likely caused an operation to fail from a chronological slice of failed events.
+// The earliest failure that isn't a cancellation caused by another failure wins, falling back to the earliest failure
+func RootCause(failures []cloudformation.StackEvent) (cloudformation.StackEvent, bool) {
+	if len(failures) == 0 {
+		return cloudformation.StackEvent{}, false
+	}
+
+	for _, failure := range failures {
+		if !isCancellation(failure) {
+			return failure, true
+		}
+	}
+
+	return failures[0], true
+}
+
+func isCancellation(event cloudformation.StackEvent) bool {
+	if event.ResourceStatusReason == nil {
+		return false
+	}
+
+	for _, reason := range cancellationReasons {
+		if strings.Contains(*event.ResourceStatusReason, reason) {
+			return true
+		}
+	}
+
+	return false
+}
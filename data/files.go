@@ -0,0 +1,69 @@
+package data
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// unmarshalConfig decodes a JSON or YAML config file into out. Files ending in .yaml or .yml are read as YAML, and any other
+// file is read as JSON first and then as YAML. YAML is converted to JSON so out decodes the same way from either format
+func unmarshalConfig(location string, contents []byte, out interface{}) error {
+	switch strings.ToLower(filepath.Ext(location)) {
+	case ".yaml", ".yml":
+		return unmarshalYAML(contents, out)
+	}
+
+	if err := json.Unmarshal(contents, out); err == nil {
+		return nil
+	}
+
+	return unmarshalYAML(contents, out)
+}
+
+// unmarshalEntries decodes a JSON or YAML file of entries holding string values, such as parameters or tags, into out. Values
+// must be strings unless coerce is set, when number and boolean values under valueKey are converted as coerceValues describes.
+// A null value is rejected either way, rather than decoding to a missing value. Parameters and tags are both read through it,
+// so they accept the same values
+func unmarshalEntries(location string, contents []byte, valueKey string, coerce bool, out interface{}) error {
+	entries := make([]map[string]json.RawMessage, 0)
+	if err := unmarshalConfig(location, contents, &entries); err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		for key, raw := range entry {
+			if strings.EqualFold(key, valueKey) && strings.TrimSpace(string(raw)) == "null" {
+				return fmt.Errorf("%s is null", valueKey)
+			}
+		}
+	}
+
+	if coerce {
+		coerceValues(entries, valueKey)
+	}
+
+	decoded, err := json.Marshal(entries)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(decoded, out)
+}
+
+func unmarshalYAML(contents []byte, out interface{}) error {
+	var document interface{}
+	if err := yaml.Unmarshal(contents, &document); err != nil {
+		return err
+	}
+
+	converted, err := json.Marshal(document)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(converted, out)
+}
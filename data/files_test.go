@@ -0,0 +1,58 @@
+package data
+
+import (
+	"testing"
+)
+
+func TestTagsAndParametersAcceptTheSameValues(t *testing.T) {
+	values := []string{`"cirrus"`, `""`, `3`, `1.0`, `1e3`, `true`, `false`, `null`, `[1]`, `{"a": "b"}`}
+
+	for _, coerce := range []bool{false, true} {
+		for _, value := range values {
+			tagsFile := writeTempFile(t, "tags.json", `[{"Key": "Size", "Value": `+value+`}]`)
+			parametersFile := writeTempFile(t, "parameters.json", `[{"ParameterKey": "Size", "ParameterValue": `+value+`}]`)
+
+			tags, tagsErr := GetTags(tagsFile, coerce)
+			parameters, parametersErr := GetParameters(parametersFile, coerce)
+
+			if (tagsErr == nil) != (parametersErr == nil) {
+				t.Errorf("with coerce %t, expected tags and parameters to agree on %s, got %v and %v", coerce, value, tagsErr, parametersErr)
+				continue
+			}
+
+			if tagsErr != nil {
+				continue
+			}
+
+			if *tags[0].Value != *parameters[0].ParameterValue {
+				t.Errorf("with coerce %t, expected %s to convert alike, got %q and %q", coerce, value, *tags[0].Value, *parameters[0].ParameterValue)
+			}
+		}
+	}
+}
+
+func TestStrictValuesAreOnlyStrings(t *testing.T) {
+	tests := []struct {
+		value  string
+		strict bool
+		coerce bool
+	}{
+		{value: `"cirrus"`, strict: true, coerce: true},
+		{value: `3`, coerce: true},
+		{value: `true`, coerce: true},
+		{value: `null`},
+		{value: `[1]`},
+	}
+
+	for _, test := range tests {
+		location := writeTempFile(t, "tags.yaml", "- Key: Size\n  Value: "+test.value+"\n")
+
+		if _, err := GetTags(location, false); (err == nil) != test.strict {
+			t.Errorf("expected %s to be accepted strictly: %t, got %v", test.value, test.strict, err)
+		}
+
+		if _, err := GetTags(location, true); (err == nil) != test.coerce {
+			t.Errorf("expected %s to be accepted with coercion: %t, got %v", test.value, test.coerce, err)
+		}
+	}
+}
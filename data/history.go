@@ -0,0 +1,96 @@
+package data
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudtrail"
+)
+
+// HistoryEntry is a change made to a stack, as recorded by CloudTrail
+type HistoryEntry struct {
+	Time      time.Time `json:"time"`
+	Principal string    `json:"principal"`
+	Operation string    `json:"operation"`
+	ErrorCode string    `json:"errorCode,omitempty"`
+}
+
+// cloudTrailRecord is the part of a CloudTrail event's JSON record that identifies the caller and the stack
+type cloudTrailRecord struct {
+	UserIdentity struct {
+		ARN string `json:"arn"`
+	} `json:"userIdentity"`
+	RequestParameters struct {
+		StackName string `json:"stackName"`
+	} `json:"requestParameters"`
+	ResponseElements struct {
+		StackID string `json:"stackId"`
+	} `json:"responseElements"`
+	ErrorCode string `json:"errorCode"`
+}
+
+// DeployHistory picks the changes made to a stack out of CloudFormation's CloudTrail events, newest first. Read-only calls
+// are skipped, and a stack matches by its name or ID, so the history of a deleted stack can still be found by name
+func DeployHistory(events []cloudtrail.Event, info StackInfo) []HistoryEntry {
+	entries := make([]HistoryEntry, 0)
+
+	for _, event := range events {
+		if event.ReadOnly != nil && *event.ReadOnly == "true" {
+			continue
+		}
+
+		if event.CloudTrailEvent == nil || event.EventName == nil || event.EventTime == nil {
+			continue
+		}
+
+		var record cloudTrailRecord
+		if err := json.Unmarshal([]byte(*event.CloudTrailEvent), &record); err != nil {
+			continue
+		}
+
+		if !recordMatchesStack(record, event.Resources, info) {
+			continue
+		}
+
+		principal := record.UserIdentity.ARN
+		if principal == "" && event.Username != nil {
+			principal = *event.Username
+		}
+
+		entries = append(entries, HistoryEntry{
+			Time:      *event.EventTime,
+			Principal: principal,
+			Operation: *event.EventName,
+			ErrorCode: record.ErrorCode,
+		})
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Time.After(entries[j].Time)
+	})
+
+	return entries
+}
+
+func recordMatchesStack(record cloudTrailRecord, resources []cloudtrail.Resource, info StackInfo) bool {
+	names := []string{record.RequestParameters.StackName, record.ResponseElements.StackID}
+
+	for _, resource := range resources {
+		if resource.ResourceName != nil {
+			names = append(names, *resource.ResourceName)
+		}
+	}
+
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+
+		if name == info.StackName || (info.StackID != "" && name == info.StackID) {
+			return true
+		}
+	}
+
+	return false
+}
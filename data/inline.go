@@ -0,0 +1,101 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/colors"
+)
+
+// ParametersFromFlags parses parameters given on the command line as Key=Value. Every malformed or repeated key is reported at once
+func ParametersFromFlags(values []string) ([]cloudformation.Parameter, error) {
+	pairs, err := splitInlinePairs(values, "parameter")
+	if err != nil {
+		return nil, err
+	}
+
+	parameters := make([]cloudformation.Parameter, 0)
+
+	for _, pair := range pairs {
+		parameters = append(parameters, cloudformation.Parameter{
+			ParameterKey:   aws.String(pair[0]),
+			ParameterValue: aws.String(pair[1]),
+		})
+	}
+
+	return parameters, nil
+}
+
+// TagsFromFlags parses tags given on the command line as Key=Value. Every malformed or repeated key is reported at once
+func TagsFromFlags(values []string) ([]cloudformation.Tag, error) {
+	pairs, err := splitInlinePairs(values, "tag")
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]cloudformation.Tag, 0)
+
+	for _, pair := range pairs {
+		tags = append(tags, cloudformation.Tag{
+			Key:   aws.String(pair[0]),
+			Value: aws.String(pair[1]),
+		})
+	}
+
+	return tags, nil
+}
+
+// MergeTags combines tag sources in order of precedence, lowest first, like MergeParameters. For up, tags given with --tag
+// override the tags file
+func MergeTags(sources ...[]cloudformation.Tag) []cloudformation.Tag {
+	merged := make([]cloudformation.Tag, 0)
+	positions := make(map[string]int)
+
+	for _, source := range sources {
+		for _, tag := range source {
+			key := *tag.Key
+
+			if position, ok := positions[key]; ok {
+				merged[position] = tag
+				continue
+			}
+
+			positions[key] = len(merged)
+			merged = append(merged, tag)
+		}
+	}
+
+	return merged
+}
+
+// splitInlinePairs splits each value on its first =, so values may contain = themselves. Keys must be non-empty and given once
+func splitInlinePairs(values []string, kind string) ([][2]string, error) {
+	pairs := make([][2]string, 0)
+	problems := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, value := range values {
+		parts := strings.SplitN(value, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			problems = append(problems, fmt.Sprintf("%s is not of the form Key=Value", value))
+			continue
+		}
+
+		if seen[parts[0]] {
+			problems = append(problems, fmt.Sprintf("%s is given more than once", parts[0]))
+			continue
+		}
+
+		seen[parts[0]] = true
+		pairs = append(pairs, [2]string{parts[0], parts[1]})
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New(colors.Error(fmt.Sprintf("Unable to read --%s values:\n  %s", kind, strings.Join(problems, "\n  "))))
+	}
+
+	return pairs, nil
+}
@@ -0,0 +1,45 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var metricLabelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// FormatMetrics renders the summary of an operation in the Prometheus text exposition format, labeled by stack name,
+// for a node_exporter textfile collector
+func FormatMetrics(summary DeploySummary) string {
+	stack := fmt.Sprintf(`stack="%s"`, metricLabelEscaper.Replace(summary.StackName))
+
+	success := 0
+	if summary.Succeeded {
+		success = 1
+	}
+
+	var metrics strings.Builder
+
+	metrics.WriteString("# HELP cirrus_deploy_duration_seconds How long the stack operation took.\n")
+	metrics.WriteString("# TYPE cirrus_deploy_duration_seconds gauge\n")
+	metrics.WriteString(fmt.Sprintf("cirrus_deploy_duration_seconds{%s} %g\n", stack, summary.DurationSeconds))
+
+	metrics.WriteString("# HELP cirrus_deploy_success Whether the stack operation succeeded.\n")
+	metrics.WriteString("# TYPE cirrus_deploy_success gauge\n")
+	metrics.WriteString(fmt.Sprintf("cirrus_deploy_success{%s} %d\n", stack, success))
+
+	statuses := make([]string, 0)
+	for status := range summary.ResourceCounts {
+		statuses = append(statuses, status)
+	}
+
+	sort.Strings(statuses)
+
+	metrics.WriteString("# HELP cirrus_deploy_resources Resources of the stack operation, by their final status.\n")
+	metrics.WriteString("# TYPE cirrus_deploy_resources gauge\n")
+	for _, status := range statuses {
+		metrics.WriteString(fmt.Sprintf("cirrus_deploy_resources{%s,status=\"%s\"} %d\n", stack, metricLabelEscaper.Replace(status), summary.ResourceCounts[status]))
+	}
+
+	return metrics.String()
+}
@@ -0,0 +1,48 @@
+package data
+
+import (
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// NestedKeySeparator joins the logical IDs of the nested stack resources leading to a row in its key, e.g. Network/Vpc
+const NestedKeySeparator string = "/"
+
+// IsStackStatusEvent determines if an event reports the status of the stack it belongs to, rather than of one of its nested
+// stack resources, which share the resource type
+func IsStackStatusEvent(event cloudformation.StackEvent) bool {
+	return *event.ResourceType == CloudformationStackResource && event.PhysicalResourceId != nil && event.StackId != nil &&
+		*event.PhysicalResourceId == *event.StackId
+}
+
+// NestedStackID returns the ID of the stack created by a nested stack resource event, once CloudFormation has assigned one
+func NestedStackID(event cloudformation.StackEvent) (string, bool) {
+	if *event.ResourceType != CloudformationStackResource || IsStackStatusEvent(event) || event.PhysicalResourceId == nil {
+		return "", false
+	}
+
+	if !IsStackID(*event.PhysicalResourceId) {
+		return "", false
+	}
+
+	return *event.PhysicalResourceId, true
+}
+
+// NestedRowKey is the key of the row of a resource in a nested stack, below the key of the nested stack resource
+func NestedRowKey(parent string, logicalID string) string {
+	if parent == "" {
+		return logicalID
+	}
+
+	return parent + NestedKeySeparator + logicalID
+}
+
+// Depth is how many nested stacks deep the row's resource is, zero for resources of the watched stack itself
+func (row DisplayRow) Depth() int {
+	if row.Parent == "" {
+		return 0
+	}
+
+	return strings.Count(row.Parent, NestedKeySeparator) + 1
+}
@@ -0,0 +1,79 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/colors"
+)
+
+// ParametersFromOutputs reads a JSON file of output key to output value and maps the selected outputs to parameters.
+// Each mapping is ParameterKey=OutputKey. Every malformed mapping and missing output is reported at once
+func ParametersFromOutputs(location string, mappings []string) ([]cloudformation.Parameter, error) {
+	contents, err := ioutil.ReadFile(location)
+	if err != nil {
+		return nil, err
+	}
+
+	outputs := make(map[string]string)
+	if err := json.Unmarshal(contents, &outputs); err != nil {
+		return nil, errors.New(colors.Error(fmt.Sprintf("Unable to load outputs %s. Outputs must be a JSON object of string keys and values", location)))
+	}
+
+	parameters := make([]cloudformation.Parameter, 0)
+	problems := make([]string, 0)
+
+	for _, mapping := range mappings {
+		parts := strings.SplitN(mapping, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			problems = append(problems, fmt.Sprintf("%s is not of the form ParameterKey=OutputKey", mapping))
+			continue
+		}
+
+		value, ok := outputs[parts[1]]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("%s maps output %s, which isn't in %s", parts[0], parts[1], location))
+			continue
+		}
+
+		parameters = append(parameters, cloudformation.Parameter{
+			ParameterKey:   aws.String(parts[0]),
+			ParameterValue: aws.String(value),
+		})
+	}
+
+	if len(problems) > 0 {
+		return nil, errors.New(colors.Error("Unable to map outputs to parameters:\n  " + strings.Join(problems, "\n  ")))
+	}
+
+	return parameters, nil
+}
+
+// MergeParameters combines parameter sources in order of precedence, lowest first. The last source to set a key wins, and keys
+// keep the position they first appeared in, so the result is deterministic. For up, the precedence is previously deployed values,
+// the parameters file, --parameters-env-file, --map, and then --parameter
+func MergeParameters(sources ...[]cloudformation.Parameter) []cloudformation.Parameter {
+	merged := make([]cloudformation.Parameter, 0)
+	positions := make(map[string]int)
+
+	for _, source := range sources {
+		for _, parameter := range source {
+			key := *parameter.ParameterKey
+
+			if position, ok := positions[key]; ok {
+				merged[position] = parameter
+				continue
+			}
+
+			positions[key] = len(merged)
+			merged = append(merged, parameter)
+		}
+	}
+
+	return merged
+}
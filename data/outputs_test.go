@@ -0,0 +1,59 @@
+package data
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// parameters builds parameters from alternating keys and values
+func parameters(keysAndValues ...string) []cloudformation.Parameter {
+	built := make([]cloudformation.Parameter, 0)
+
+	for i := 0; i+1 < len(keysAndValues); i += 2 {
+		built = append(built, cloudformation.Parameter{
+			ParameterKey:   aws.String(keysAndValues[i]),
+			ParameterValue: aws.String(keysAndValues[i+1]),
+		})
+	}
+
+	return built
+}
+
+func parameterKeys(merged []cloudformation.Parameter) []string {
+	keys := make([]string, 0)
+
+	for _, parameter := range merged {
+		keys = append(keys, *parameter.ParameterKey)
+	}
+
+	return keys
+}
+
+func TestMergeParametersPrecedence(t *testing.T) {
+	fromFile := parameters("Environment", "file", "Size", "file", "Name", "file", "Owner", "file")
+	fromEnv := parameters("Size", "env", "Name", "env", "Owner", "env")
+	mapped := parameters("Name", "outputs", "Owner", "outputs", "Vpc", "outputs")
+	inline := parameters("Owner", "flag")
+
+	merged := MergeParameters(fromFile, fromEnv, mapped, inline)
+
+	expected := map[string]string{
+		"Environment": "file",
+		"Size":        "env",
+		"Name":        "outputs",
+		"Owner":       "flag",
+		"Vpc":         "outputs",
+	}
+
+	if got := ParameterValues(merged); !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected %v, got %v", expected, got)
+	}
+
+	// keys keep the position they first appeared in
+	if keys := parameterKeys(merged); !reflect.DeepEqual(keys, []string{"Environment", "Size", "Name", "Owner", "Vpc"}) {
+		t.Errorf("expected keys in first appearance order, got %v", keys)
+	}
+}
@@ -0,0 +1,35 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/blueseph/cirrus/colors"
+)
+
+// stackPolicy is the part of a stack policy checked before it's sent, which CloudFormation rejects without statements
+type stackPolicy struct {
+	Statement []interface{} `json:"Statement"`
+}
+
+// GetStackPolicy gets the stack policy from the JSON file at the location provided. If the file doesn't exist, the policy is
+// empty and the stack keeps the policy it has
+func GetStackPolicy(location string) (string, error) {
+	invalidJSON := "Unable to load stack policy. The stack policy must be a valid JSON object with a Statement list"
+	docsMessage := "https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/protect-stack-resources.html"
+	errorMessage := fmt.Sprintf("%s \n %s", colors.Error(invalidJSON), colors.Docs(docsMessage))
+
+	policy, err := ioutil.ReadFile(location)
+	if err != nil {
+		return "", missingFileError("stack policy", location, err)
+	}
+
+	var parsed stackPolicy
+	if err := json.Unmarshal(policy, &parsed); err != nil || len(parsed.Statement) == 0 {
+		return "", errors.New(errorMessage)
+	}
+
+	return string(policy), nil
+}
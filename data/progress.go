@@ -0,0 +1,106 @@
+package data
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/utils"
+)
+
+// ProgressPercent estimates how far along an operation is, as the percentage of planned rows that reached a terminal event status.
+// Active change rows and event rows make up the denominator. It is an approximation, as rollbacks and cleanup produce events that were never planned
+func ProgressPercent(rows map[string]DisplayRow) float64 {
+	total, completed := 0, 0
+
+	for _, row := range rows {
+		if !row.Active && row.Source != DisplayRowSourceEvent {
+			continue
+		}
+
+		total++
+
+		if row.Source == DisplayRowSourceEvent && !utils.ContainsResourceStatus(PendingEventStatus, row.Status) {
+			completed++
+		}
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	return float64(completed) / float64(total) * 100
+}
+
+// PendingResources lists the logical IDs of rows whose latest event is still in progress, sorted
+func PendingResources(rows map[string]DisplayRow) []string {
+	pending := make([]string, 0)
+
+	for key, row := range rows {
+		if row.Source == DisplayRowSourceEvent && utils.ContainsResourceStatus(PendingEventStatus, row.Status) {
+			pending = append(pending, key)
+		}
+	}
+
+	sort.Strings(pending)
+
+	return pending
+}
+
+// IsOperationStarted determines if any rows have been activated or produced by events, meaning the operation is underway
+func IsOperationStarted(rows map[string]DisplayRow) bool {
+	for _, row := range rows {
+		if row.Active || row.Source == DisplayRowSourceEvent {
+			return true
+		}
+	}
+
+	return false
+}
+
+// IsOperationStart determines if an event is the first event of a stack operation
+func IsOperationStart(event cloudformation.StackEvent) bool {
+	if !IsStackStatusEvent(event) {
+		return false
+	}
+
+	switch cloudformation.StackStatus(event.ResourceStatus) {
+	case cloudformation.StackStatusCreateInProgress, cloudformation.StackStatusUpdateInProgress,
+		cloudformation.StackStatusDeleteInProgress, cloudformation.StackStatusImportInProgress:
+		return true
+	}
+
+	return false
+}
+
+// OperationStartTime finds when the most recent stack operation began, from events ordered newest first. It is false if the start
+// isn't among the events, e.g. when they were bounded to fewer than the operation produced
+func OperationStartTime(events []cloudformation.StackEvent) (time.Time, bool) {
+	for _, event := range events {
+		if IsOperationStart(event) {
+			return *event.Timestamp, true
+		}
+	}
+
+	return time.Time{}, false
+}
+
+// ProjectResourceCount estimates how many resources a stack will have once its change set executes
+func ProjectResourceCount(current int, changes []cloudformation.Change) int {
+	projected := current
+
+	for _, change := range changes {
+		if !IsResourceChange(change) {
+			continue
+		}
+
+		switch change.ResourceChange.Action {
+		case cloudformation.ChangeActionAdd, cloudformation.ChangeActionImport:
+			projected++
+		case cloudformation.ChangeActionRemove:
+			projected--
+		}
+	}
+
+	return projected
+}
@@ -0,0 +1,33 @@
+package data
+
+import "regexp"
+
+// ReasonDoc links a documentation page to the failure reasons matching its pattern
+type ReasonDoc struct {
+	Pattern *regexp.Regexp
+	URL     string
+}
+
+// ReasonDocs are checked in order against a failure reason, and the first match gives its documentation link. Add a ReasonDoc
+// here to link a new kind of failure
+var ReasonDocs = []ReasonDoc{
+	{regexp.MustCompile(`(?i)requires capabilities`), "https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/using-iam-template.html#using-iam-capabilities"},
+	{regexp.MustCompile(`(?i)not authorized to perform|access ?denied`), "https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/using-iam-template.html"},
+	{regexp.MustCompile(`(?i)rate exceeded|throttl`), "https://docs.aws.amazon.com/general/latest/gr/api-retries.html"},
+	{regexp.MustCompile(`(?i)limit ?exceeded|exceeded.* limit|quota`), "https://docs.aws.amazon.com/general/latest/gr/aws_service_limits.html"},
+	{regexp.MustCompile(`(?i)already exists`), "https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/resource-import.html"},
+	{regexp.MustCompile(`(?i)export .* in use`), "https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/using-cfn-stack-exports.html"},
+	{regexp.MustCompile(`(?i)bucket .*not empty`), "https://docs.aws.amazon.com/AmazonS3/latest/userguide/empty-bucket.html"},
+	{regexp.MustCompile(`(?i)did not stabilize|timed out`), "https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/troubleshooting.html"},
+}
+
+// DocsForReason returns the documentation link of the first ReasonDoc matching a failure reason
+func DocsForReason(reason string) (string, bool) {
+	for _, doc := range ReasonDocs {
+		if doc.Pattern.MatchString(reason) {
+			return doc.URL, true
+		}
+	}
+
+	return "", false
+}
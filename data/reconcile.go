@@ -0,0 +1,124 @@
+package data
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/utils"
+)
+
+// Outcome is what actually happened to a resource during an operation, judged by its last event
+type Outcome string
+
+const (
+	// OutcomeAdded is a resource that was created
+	OutcomeAdded Outcome = "added"
+
+	// OutcomeModified is a resource that was updated, or replaced and its old resource cleaned up
+	OutcomeModified Outcome = "modified"
+
+	// OutcomeRemoved is a resource that was deleted, or skipped by a retaining DeletionPolicy
+	OutcomeRemoved Outcome = "removed"
+
+	// OutcomeImported is a resource that was imported
+	OutcomeImported Outcome = "imported"
+
+	// OutcomeFailed is a resource whose last event failed
+	OutcomeFailed Outcome = "failed"
+
+	// OutcomeRolledBack is a resource whose change was reverted
+	OutcomeRolledBack Outcome = "rolled back"
+
+	// OutcomeInProgress is a resource whose last event was still in progress when the watch ended
+	OutcomeInProgress Outcome = "in progress"
+
+	// OutcomeUnchanged is a resource that produced no events
+	OutcomeUnchanged Outcome = "unchanged"
+)
+
+// Outcomes are every outcome, in the order a reconciliation lists them
+var Outcomes = []Outcome{OutcomeAdded, OutcomeModified, OutcomeRemoved, OutcomeImported, OutcomeFailed, OutcomeRolledBack, OutcomeInProgress, OutcomeUnchanged}
+
+// plannedOutcomes is the outcome each change set action should have
+var plannedOutcomes = map[cloudformation.ChangeAction]Outcome{
+	cloudformation.ChangeActionAdd:    OutcomeAdded,
+	cloudformation.ChangeActionModify: OutcomeModified,
+	cloudformation.ChangeActionRemove: OutcomeRemoved,
+	cloudformation.ChangeActionImport: OutcomeImported,
+}
+
+// Reconciliation compares the changes planned by a change set with what the operation's events show happened
+type Reconciliation struct {
+	Planned       map[Outcome]int
+	Actual        map[Outcome]int
+	Discrepancies []string
+}
+
+// Reconcile compares the planned rows of a change set with the rows after the operation. A resource is a discrepancy when its
+// outcome differs from its planned action, or when it changed without being planned. Resources of nested stacks aren't part of
+// the plan and are left out
+func Reconcile(planned map[string]DisplayRow, actual map[string]DisplayRow) Reconciliation {
+	reconciliation := Reconciliation{
+		Planned:       make(map[Outcome]int),
+		Actual:        make(map[Outcome]int),
+		Discrepancies: make([]string, 0),
+	}
+
+	for key, row := range planned {
+		expected := plannedOutcomes[row.Action]
+		reconciliation.Planned[expected]++
+
+		outcome := OutcomeUnchanged
+		if final, ok := actual[key]; ok && final.Source == DisplayRowSourceEvent {
+			outcome = rowOutcome(final)
+
+			// a replacement ends with the old resource deleted during cleanup
+			if outcome == OutcomeRemoved && row.Action == cloudformation.ChangeActionModify && row.Replacement != cloudformation.ReplacementFalse {
+				outcome = OutcomeModified
+			}
+		}
+
+		reconciliation.Actual[outcome]++
+
+		if outcome != expected {
+			reconciliation.Discrepancies = append(reconciliation.Discrepancies, fmt.Sprintf("%s was planned to be %s, but was %s", key, expected, outcome))
+		}
+	}
+
+	for key, row := range actual {
+		if _, ok := planned[key]; ok || row.Source != DisplayRowSourceEvent || row.Parent != "" {
+			continue
+		}
+
+		outcome := rowOutcome(row)
+		reconciliation.Actual[outcome]++
+		reconciliation.Discrepancies = append(reconciliation.Discrepancies, fmt.Sprintf("%s was not planned to change, but was %s", key, outcome))
+	}
+
+	sort.Strings(reconciliation.Discrepancies)
+
+	return reconciliation
+}
+
+// rowOutcome judges what happened to a resource from the status of its last event
+func rowOutcome(row DisplayRow) Outcome {
+	switch row.Status {
+	case cloudformation.ResourceStatusCreateComplete:
+		return OutcomeAdded
+	case cloudformation.ResourceStatusUpdateComplete:
+		return OutcomeModified
+	case cloudformation.ResourceStatusDeleteComplete, cloudformation.ResourceStatusDeleteSkipped:
+		return OutcomeRemoved
+	case cloudformation.ResourceStatusImportComplete:
+		return OutcomeImported
+	case cloudformation.ResourceStatusImportRollbackComplete, ResourceStatusRollbackComplete, ResourceStatusUpdateRollbackComplete:
+		return OutcomeRolledBack
+	}
+
+	if utils.ContainsResourceStatus(NegativeEventStatus, row.Status) {
+		return OutcomeFailed
+	}
+
+	return OutcomeInProgress
+}
@@ -0,0 +1,106 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/colors"
+)
+
+// redactor masks the values of sensitive parameters in text cirrus prints or writes
+var redactor = strings.NewReplacer()
+
+// sensitiveKeys are the keys of the parameters RedactParameters found sensitive, whose values RedactParameter masks whole
+var sensitiveKeys = make(map[string]bool)
+
+// minRedactedLength is the shortest value masked. Shorter values, like a flag of "1" or "true", would mask unrelated text
+// such as regions and ARNs and reveal nothing worth hiding
+const minRedactedLength = 4
+
+// ParseMaskPatterns compiles patterns matching parameter keys to mask. Patterns between slashes, e.g. /^Db.*Key$/, are
+// regular expressions. Anything else is a glob where * matches any characters and ? a single character, ignoring case
+func ParseMaskPatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0)
+
+	for _, pattern := range patterns {
+		expression := "(?i)^" + strings.ReplaceAll(strings.ReplaceAll(regexp.QuoteMeta(pattern), `\*`, ".*"), `\?`, ".") + "$"
+
+		if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+			expression = pattern[1 : len(pattern)-1]
+		}
+
+		matcher, err := regexp.Compile(expression)
+		if err != nil {
+			return nil, errors.New(colors.Error(fmt.Sprintf("Invalid mask pattern %s: %s", pattern, err.Error())))
+		}
+
+		compiled = append(compiled, matcher)
+	}
+
+	return compiled, nil
+}
+
+// IsSensitiveParameter determines if a parameter is declared NoEcho in the template or its key matches a mask pattern
+func IsSensitiveParameter(key string, template Template, patterns []*regexp.Regexp) bool {
+	if declaration, ok := template.Parameters[key]; ok && strings.EqualFold(fmt.Sprint(declaration.NoEcho), "true") {
+		return true
+	}
+
+	for _, pattern := range patterns {
+		if pattern.MatchString(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RedactParameters masks the values of the sensitive parameters in everything passed through Redact or RedactParameter afterwards.
+// Redact leaves values shorter than minRedactedLength alone in free text, while RedactParameter masks them by key
+func RedactParameters(parameters []cloudformation.Parameter, template Template, patterns []*regexp.Regexp) {
+	values := make([]string, 0)
+	sensitiveKeys = make(map[string]bool)
+
+	for key, value := range ParameterValues(parameters) {
+		if !IsSensitiveParameter(key, template, patterns) {
+			continue
+		}
+
+		sensitiveKeys[key] = true
+
+		if len(value) >= minRedactedLength && value != maskedParameterValue {
+			values = append(values, value)
+		}
+	}
+
+	// longer values go first, so a value containing another is masked whole
+	sort.Slice(values, func(i, j int) bool {
+		return len(values[i]) > len(values[j])
+	})
+
+	replacements := make([]string, 0)
+	for _, value := range values {
+		replacements = append(replacements, value, maskedParameterValue)
+	}
+
+	redactor = strings.NewReplacer(replacements...)
+}
+
+// Redact masks the values of sensitive parameters in text, such as status reasons and errors from CloudFormation
+func Redact(text string) string {
+	return redactor.Replace(text)
+}
+
+// RedactParameter masks the whole value of a sensitive parameter whatever its length, and redacts the value of any other
+// parameter like text
+func RedactParameter(key string, value string) string {
+	if sensitiveKeys[key] {
+		return maskedParameterValue
+	}
+
+	return Redact(value)
+}
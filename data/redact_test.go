@@ -0,0 +1,54 @@
+package data
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRedactParameters(t *testing.T) {
+	template := Template{Parameters: map[string]TemplateParameter{
+		"DbPassword": {NoEcho: true},
+		"Replicas":   {NoEcho: "true"},
+		"Name":       {},
+	}}
+
+	patterns := []*regexp.Regexp{regexp.MustCompile("(?i)^.*secret.*$")}
+
+	RedactParameters(parameters("DbPassword", "hunter22", "Replicas", "1", "Name", "hunter22-logs", "ApiSecret", "s3cr3t"), template, patterns)
+	defer RedactParameters(nil, Template{}, nil)
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{text: "Password hunter22 is invalid", expected: "Password **** is invalid"},
+		{text: "Secret s3cr3t was rejected", expected: "Secret **** was rejected"},
+		// short values would mask regions, account IDs and counts all over the output
+		{text: "Stack in us-east-1 scaled to 1 replica", expected: "Stack in us-east-1 scaled to 1 replica"},
+		// a value that isn't sensitive is still masked where it contains a sensitive one
+		{text: "Bucket hunter22-logs", expected: "Bucket ****-logs"},
+	}
+
+	for _, test := range tests {
+		if got := Redact(test.text); got != test.expected {
+			t.Errorf("expected %q to redact to %q, got %q", test.text, test.expected, got)
+		}
+	}
+}
+
+func TestParseMaskPatterns(t *testing.T) {
+	patterns, err := ParseMaskPatterns([]string{"*Password*", "/^Db.*Key$/"})
+	if err != nil {
+		t.Fatalf("unable to parse the patterns: %s", err)
+	}
+
+	for key, sensitive := range map[string]bool{"AdminPassword": true, "adminpassword": true, "DbApiKey": true, "dbapikey": false, "Name": false} {
+		if got := IsSensitiveParameter(key, Template{}, patterns); got != sensitive {
+			t.Errorf("expected %s to be sensitive: %t", key, sensitive)
+		}
+	}
+
+	if _, err := ParseMaskPatterns([]string{"/(/"}); err == nil {
+		t.Errorf("expected an invalid regular expression to be rejected")
+	}
+}
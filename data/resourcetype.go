@@ -0,0 +1,34 @@
+package data
+
+import "strings"
+
+// awsTypePrefix starts every resource type AWS provides, as opposed to Custom:: and third party types
+const awsTypePrefix string = "AWS::"
+
+// serviceAbbreviations shorten the longest service names of AWS resource types. Each abbreviation maps back to one service,
+// so a short type can always be expanded to its full type
+var serviceAbbreviations = map[string]string{
+	"ApiGateway":             "APIGW",
+	"ApiGatewayV2":           "APIGWv2",
+	"CertificateManager":     "ACM",
+	"CloudFormation":         "CFN",
+	"CloudWatch":             "CW",
+	"ElasticLoadBalancing":   "ELB",
+	"ElasticLoadBalancingV2": "ELBv2",
+}
+
+// ShortResourceType abbreviates an AWS resource type for display, dropping the AWS:: prefix and shortening the service names in
+// serviceAbbreviations, e.g. AWS::ElasticLoadBalancingV2::LoadBalancer becomes ELBv2::LoadBalancer. Other types are unchanged
+func ShortResourceType(resourceType string) string {
+	if !strings.HasPrefix(resourceType, awsTypePrefix) {
+		return resourceType
+	}
+
+	parts := strings.SplitN(strings.TrimPrefix(resourceType, awsTypePrefix), "::", 2)
+
+	if abbreviation, ok := serviceAbbreviations[parts[0]]; ok {
+		parts[0] = abbreviation
+	}
+
+	return strings.Join(parts, "::")
+}
@@ -0,0 +1,103 @@
+package data
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// DeployResult is the structured outcome of a stack operation, for rendering or for using cirrus as a library
+type DeployResult struct {
+	StackName string
+	StackID   string
+
+	// Executed is false when the operation was declined or never started
+	Executed bool
+
+	// Status is the stack status the operation finished in
+	Status   cloudformation.StackStatus
+	Duration time.Duration
+	Rows     map[string]DisplayRow
+	Outputs  map[string]string
+	Tags     map[string]string
+
+	// TagChecks compares the tags the deploy intended to apply against Tags
+	TagChecks []TagCheck
+
+	// RootCause is the failure that most likely caused the operation to fail, if it failed
+	RootCause *cloudformation.StackEvent
+}
+
+// OutputValues converts a slice of stack outputs into a map of output key to value
+func OutputValues(outputs []cloudformation.Output) map[string]string {
+	values := make(map[string]string)
+
+	for _, output := range outputs {
+		if output.OutputKey != nil && output.OutputValue != nil {
+			values[*output.OutputKey] = *output.OutputValue
+		}
+	}
+
+	return values
+}
+
+// DeploySummary is the machine-readable summary of a stack operation
+type DeploySummary struct {
+	StackName       string            `json:"stackName"`
+	StackID         string            `json:"stackId,omitempty"`
+	Succeeded       bool              `json:"succeeded"`
+	Executed        bool              `json:"executed"`
+	Status          string            `json:"status,omitempty"`
+	DurationSeconds float64           `json:"durationSeconds"`
+	ResourceCounts  map[string]int    `json:"resourceCounts"`
+	Outputs         map[string]string `json:"outputs"`
+	RootCause       *SummaryFailure   `json:"rootCause,omitempty"`
+}
+
+// SummaryFailure is the failure that most likely caused an operation to fail
+type SummaryFailure struct {
+	LogicalResourceID string `json:"logicalResourceId"`
+	ResourceType      string `json:"resourceType"`
+	Status            string `json:"status"`
+	Reason            string `json:"reason"`
+}
+
+// Summarize builds the summary of an operation from its result and whether it succeeded. Resources are counted by their last event status
+func Summarize(result DeployResult, succeeded bool) DeploySummary {
+	summary := DeploySummary{
+		StackName:       result.StackName,
+		StackID:         result.StackID,
+		Succeeded:       succeeded,
+		Executed:        result.Executed,
+		Status:          string(result.Status),
+		DurationSeconds: result.Duration.Seconds(),
+		ResourceCounts:  make(map[string]int),
+		Outputs:         result.Outputs,
+	}
+
+	if summary.Outputs == nil {
+		summary.Outputs = make(map[string]string)
+	}
+
+	for _, row := range result.Rows {
+		if row.Source == DisplayRowSourceEvent {
+			summary.ResourceCounts[string(row.Status)]++
+		}
+	}
+
+	if result.RootCause != nil {
+		failure := SummaryFailure{
+			LogicalResourceID: *result.RootCause.LogicalResourceId,
+			ResourceType:      *result.RootCause.ResourceType,
+			Status:            string(result.RootCause.ResourceStatus),
+		}
+
+		if result.RootCause.ResourceStatusReason != nil {
+			failure.Reason = Redact(*result.RootCause.ResourceStatusReason)
+		}
+
+		summary.RootCause = &failure
+	}
+
+	return summary
+}
@@ -0,0 +1,97 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// RiskLevel is how disruptive a change is, ordered from least to most disruptive
+type RiskLevel int
+
+const (
+	// RiskSafe is an added or imported resource
+	RiskSafe RiskLevel = iota
+
+	// RiskModify is a resource updated in place
+	RiskModify
+
+	// RiskReplacement is a resource that will or may be replaced, losing its physical identity
+	RiskReplacement
+
+	// RiskDeletion is a removed resource
+	RiskDeletion
+)
+
+// riskNouns names the changes of each risk level in the summary, as singular and plural
+var riskNouns = map[RiskLevel][2]string{
+	RiskDeletion:    {"deletion", "deletions"},
+	RiskReplacement: {"replacement", "replacements"},
+	RiskModify:      {"in-place modification", "in-place modifications"},
+	RiskSafe:        {"addition", "additions"},
+}
+
+// ClassifyRisk determines the risk level of a change from its action and replacement. Conditional replacements count as
+// replacements, since they may happen
+func ClassifyRisk(row DisplayRow) RiskLevel {
+	switch row.Action {
+	case cloudformation.ChangeActionRemove:
+		return RiskDeletion
+	case cloudformation.ChangeActionModify:
+		if row.Replacement == cloudformation.ReplacementTrue || row.Replacement == cloudformation.ReplacementConditional {
+			return RiskReplacement
+		}
+
+		return RiskModify
+	}
+
+	return RiskSafe
+}
+
+// SummarizeRisk counts the changes in the rows by risk level, most disruptive first, e.g. "2 replacements, 1 deletion — review carefully".
+// The highest risk level present is returned alongside, and the summary is empty when there are no changes
+func SummarizeRisk(rows map[string]DisplayRow) (string, RiskLevel) {
+	counts := make(map[RiskLevel]int)
+	highest := RiskSafe
+
+	for _, row := range rows {
+		if row.Source == DisplayRowSourceEvent {
+			continue
+		}
+
+		risk := ClassifyRisk(row)
+		counts[risk]++
+
+		if risk > highest {
+			highest = risk
+		}
+	}
+
+	parts := make([]string, 0)
+
+	for _, risk := range []RiskLevel{RiskDeletion, RiskReplacement, RiskModify, RiskSafe} {
+		count := counts[risk]
+		if count == 0 {
+			continue
+		}
+
+		noun := riskNouns[risk][1]
+		if count == 1 {
+			noun = riskNouns[risk][0]
+		}
+
+		parts = append(parts, fmt.Sprintf("%d %s", count, noun))
+	}
+
+	if len(parts) == 0 {
+		return "", highest
+	}
+
+	summary := strings.Join(parts, ", ")
+	if highest >= RiskReplacement {
+		summary += " — review carefully"
+	}
+
+	return summary, highest
+}
@@ -0,0 +1,60 @@
+package data
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+func TestClassifyRisk(t *testing.T) {
+	tests := []struct {
+		action      cloudformation.ChangeAction
+		replacement cloudformation.Replacement
+		expected    RiskLevel
+	}{
+		{action: cloudformation.ChangeActionAdd, expected: RiskSafe},
+		{action: cloudformation.ChangeActionImport, expected: RiskSafe},
+		{action: cloudformation.ChangeActionModify, expected: RiskModify},
+		{action: cloudformation.ChangeActionModify, replacement: cloudformation.ReplacementFalse, expected: RiskModify},
+		{action: cloudformation.ChangeActionModify, replacement: cloudformation.ReplacementTrue, expected: RiskReplacement},
+		{action: cloudformation.ChangeActionModify, replacement: cloudformation.ReplacementConditional, expected: RiskReplacement},
+		{action: cloudformation.ChangeActionRemove, expected: RiskDeletion},
+		{action: cloudformation.ChangeActionRemove, replacement: cloudformation.ReplacementFalse, expected: RiskDeletion},
+	}
+
+	for _, test := range tests {
+		t.Run(fmt.Sprintf("%s replacement %q", test.action, test.replacement), func(t *testing.T) {
+			row := DisplayRow{Action: test.action, Replacement: test.replacement}
+
+			if got := ClassifyRisk(row); got != test.expected {
+				t.Errorf("expected risk %d, got %d", test.expected, got)
+			}
+		})
+	}
+}
+
+func TestSummarizeRisk(t *testing.T) {
+	rows := map[string]DisplayRow{
+		"Queue":    {Action: cloudformation.ChangeActionAdd, Source: DisplayRowSourceChangeSet},
+		"Bucket":   {Action: cloudformation.ChangeActionModify, Replacement: cloudformation.ReplacementTrue, Source: DisplayRowSourceChangeSet},
+		"Database": {Action: cloudformation.ChangeActionModify, Replacement: cloudformation.ReplacementConditional, Source: DisplayRowSourceChangeSet},
+		"Topic":    {Action: cloudformation.ChangeActionRemove, Source: DisplayRowSourceChangeSet},
+		// events aren't changes of the change set
+		"Function": {Action: cloudformation.ChangeActionRemove, Source: DisplayRowSourceEvent},
+	}
+
+	summary, highest := SummarizeRisk(rows)
+
+	if expected := "1 deletion, 2 replacements, 1 addition — review carefully"; summary != expected {
+		t.Errorf("expected %q, got %q", expected, summary)
+	}
+
+	if highest != RiskDeletion {
+		t.Errorf("expected the highest risk to be a deletion, got %d", highest)
+	}
+
+	if summary, _ := SummarizeRisk(map[string]DisplayRow{}); summary != "" {
+		t.Errorf("expected no summary without changes, got %q", summary)
+	}
+}
@@ -0,0 +1,39 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// ValidateParametersSchema validates parameters against the JSON schema at the given location. The parameters are validated
+// as a single object of parameter key to value, and every violation is reported at once
+func ValidateParametersSchema(location string, parameters []cloudformation.Parameter) error {
+	docsMessage := "https://json-schema.org/understanding-json-schema/"
+
+	schema, err := ioutil.ReadFile(location)
+	if err != nil {
+		return err
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewGoLoader(ParameterValues(parameters)))
+	if err != nil {
+		invalidSchema := fmt.Sprintf("Unable to load parameters schema %s: %s", location, err.Error())
+		return errors.New(fmt.Sprintf("%s \n %s", colors.Error(invalidSchema), colors.Docs(docsMessage)))
+	}
+
+	if result.Valid() {
+		return nil
+	}
+
+	errorMessage := colors.Error(fmt.Sprintf("Parameters violate the schema %s:", location))
+	for _, violation := range result.Errors() {
+		errorMessage += "\n  " + colors.Magenta(violation.Field()) + " - " + violation.Description()
+	}
+
+	return errors.New(errorMessage)
+}
@@ -0,0 +1,51 @@
+package data
+
+import (
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// StackListing is a stack as shown by the list command
+type StackListing struct {
+	StackName string
+	Status    cloudformation.StackStatus
+	Created   time.Time
+	Updated   time.Time
+}
+
+// IsStaleReview reports whether the stack was created by a change set that was never executed
+func (listing StackListing) IsStaleReview() bool {
+	return listing.Status == cloudformation.StackStatusReviewInProgress
+}
+
+// ListStacks converts stack summaries into listings ordered by name, leaving out deleted stacks unless includeDeleted
+func ListStacks(summaries []cloudformation.StackSummary, includeDeleted bool) []StackListing {
+	listings := make([]StackListing, 0)
+
+	for _, summary := range summaries {
+		if summary.StackStatus == cloudformation.StackStatusDeleteComplete && !includeDeleted {
+			continue
+		}
+
+		listing := StackListing{
+			StackName: *summary.StackName,
+			Status:    summary.StackStatus,
+			Created:   *summary.CreationTime,
+			Updated:   *summary.CreationTime,
+		}
+
+		if summary.LastUpdatedTime != nil {
+			listing.Updated = *summary.LastUpdatedTime
+		}
+
+		listings = append(listings, listing)
+	}
+
+	sort.Slice(listings, func(i, j int) bool {
+		return listings[i].StackName < listings[j].StackName
+	})
+
+	return listings
+}
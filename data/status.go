@@ -0,0 +1,66 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/colors"
+)
+
+// KnownStackStatus is every status a stack can be in
+var KnownStackStatus = []cloudformation.StackStatus{
+	cloudformation.StackStatusCreateInProgress,
+	cloudformation.StackStatusCreateFailed,
+	cloudformation.StackStatusCreateComplete,
+	cloudformation.StackStatusRollbackInProgress,
+	cloudformation.StackStatusRollbackFailed,
+	cloudformation.StackStatusRollbackComplete,
+	cloudformation.StackStatusDeleteInProgress,
+	cloudformation.StackStatusDeleteFailed,
+	cloudformation.StackStatusDeleteComplete,
+	cloudformation.StackStatusUpdateInProgress,
+	cloudformation.StackStatusUpdateCompleteCleanupInProgress,
+	cloudformation.StackStatusUpdateComplete,
+	cloudformation.StackStatusUpdateRollbackInProgress,
+	cloudformation.StackStatusUpdateRollbackFailed,
+	cloudformation.StackStatusUpdateRollbackCompleteCleanupInProgress,
+	cloudformation.StackStatusUpdateRollbackComplete,
+	cloudformation.StackStatusReviewInProgress,
+	cloudformation.StackStatusImportInProgress,
+	cloudformation.StackStatusImportComplete,
+	cloudformation.StackStatusImportRollbackInProgress,
+	cloudformation.StackStatusImportRollbackFailed,
+	cloudformation.StackStatusImportRollbackComplete,
+}
+
+// ParseStackStatuses converts status names into stack statuses, ignoring case. Every unknown status is reported at once
+func ParseStackStatuses(names []string) ([]cloudformation.StackStatus, error) {
+	known := make(map[string]cloudformation.StackStatus)
+	for _, status := range KnownStackStatus {
+		known[string(status)] = status
+	}
+
+	statuses := make([]cloudformation.StackStatus, 0)
+	unknown := make([]string, 0)
+
+	for _, name := range names {
+		status, ok := known[strings.ToUpper(name)]
+		if !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	if len(unknown) > 0 {
+		docsMessage := "https://docs.aws.amazon.com/AWSCloudFormation/latest/APIReference/API_Stack.html"
+		unknownStatuses := fmt.Sprintf("Unknown stack status %s", strings.Join(unknown, ", "))
+
+		return nil, errors.New(fmt.Sprintf("%s \n %s", colors.Error(unknownStatuses), colors.Docs(docsMessage)))
+	}
+
+	return statuses, nil
+}
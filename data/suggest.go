@@ -0,0 +1,123 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/blueseph/cirrus/colors"
+)
+
+// maxSuggestions bounds how many similar files are suggested for a missing one
+const maxSuggestions int = 3
+
+// SuggestPaths returns files in the directory of a missing path whose names are within a few edits of its name, closest
+// first, like git suggests commands. It's best-effort, so an unreadable directory gives no suggestions
+func SuggestPaths(location string) []string {
+	dir, name := filepath.Split(location)
+
+	entries, err := ioutil.ReadDir(filepath.Clean(dir + "."))
+	if err != nil {
+		return nil
+	}
+
+	// allow about one typo per five characters, and at least two
+	threshold := len(name) / 5
+	if threshold < 2 {
+		threshold = 2
+	}
+
+	distances := make(map[string]int)
+	candidates := make([]string, 0)
+
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == name {
+			continue
+		}
+
+		distance := editDistance(strings.ToLower(name), strings.ToLower(entry.Name()))
+		if distance <= threshold {
+			distances[entry.Name()] = distance
+			candidates = append(candidates, entry.Name())
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if distances[candidates[i]] == distances[candidates[j]] {
+			return candidates[i] < candidates[j]
+		}
+
+		return distances[candidates[i]] < distances[candidates[j]]
+	})
+
+	if len(candidates) > maxSuggestions {
+		candidates = candidates[:maxSuggestions]
+	}
+
+	suggestions := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		suggestions = append(suggestions, dir+candidate)
+	}
+
+	return suggestions
+}
+
+// missingFileError explains a file that couldn't be read when similarly named files exist beside it, as it's likely a typo.
+// Otherwise nil is returned and the file is treated as optional, as it is when no location was given
+func missingFileError(kind string, location string, err error) error {
+	if location == "" || !os.IsNotExist(err) {
+		return nil
+	}
+
+	suggestions := SuggestPaths(location)
+	if len(suggestions) == 0 {
+		return nil
+	}
+
+	return errors.New(colors.Error(fmt.Sprintf("Unable to load %s: %s does not exist. Did you mean %s?", kind, location, strings.Join(suggestions, " or "))))
+}
+
+// editDistance is the Levenshtein distance between two strings, the fewest insertions, deletions and substitutions turning one into the other
+func editDistance(a string, b string) int {
+	first, second := []rune(a), []rune(b)
+
+	previous := make([]int, len(second)+1)
+	current := make([]int, len(second)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(first); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(second); j++ {
+			cost := 1
+			if first[i-1] == second[j-1] {
+				cost = 0
+			}
+
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(second)]
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+
+	for _, value := range values[1:] {
+		if value < min {
+			min = value
+		}
+	}
+
+	return min
+}
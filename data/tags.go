@@ -0,0 +1,122 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/colors"
+)
+
+const (
+	// MaxStackTags is the most tags CloudFormation accepts on a stack
+	MaxStackTags int = 50
+
+	// MaxTagKeyLength is the most characters in a stack tag key
+	MaxTagKeyLength int = 128
+
+	// MaxTagValueLength is the most characters in a stack tag value
+	MaxTagValueLength int = 256
+
+	// reservedTagPrefix starts the keys of tags only AWS may set
+	reservedTagPrefix string = "aws:"
+)
+
+// TagCheck is the comparison of one intended stack tag against the tags CloudFormation reports
+type TagCheck struct {
+	Key      string
+	Intended string
+	Applied  string
+	Found    bool
+}
+
+// Matched reports whether the tag was applied with the intended value
+func (check TagCheck) Matched() bool {
+	return check.Found && check.Applied == check.Intended
+}
+
+// TagValues converts a slice of stack tags into a map of tag key to value
+func TagValues(tags []cloudformation.Tag) map[string]string {
+	values := make(map[string]string)
+
+	for _, tag := range tags {
+		if tag.Key != nil && tag.Value != nil {
+			values[*tag.Key] = *tag.Value
+		}
+	}
+
+	return values
+}
+
+// TagsDiffer determines if two sets of stack tags differ in any key or value, whatever their order
+func TagsDiffer(deployed []cloudformation.Tag, local []cloudformation.Tag) bool {
+	return !reflect.DeepEqual(TagValues(deployed), TagValues(local))
+}
+
+// VerifyTags compares the intended stack tags against the applied ones, ordered by key. Applied tags that weren't intended are ignored, since CloudFormation may add its own
+func VerifyTags(intended []cloudformation.Tag, applied map[string]string) []TagCheck {
+	checks := make([]TagCheck, 0)
+
+	for key, value := range TagValues(intended) {
+		appliedValue, found := applied[key]
+
+		checks = append(checks, TagCheck{
+			Key:      key,
+			Intended: value,
+			Applied:  appliedValue,
+			Found:    found,
+		})
+	}
+
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].Key < checks[j].Key
+	})
+
+	return checks
+}
+
+// ValidateTags checks the tags against the limits CloudFormation places on stack tags, so a mistake fails before deploying
+// rather than in the change set. Every offending tag is reported at once
+func ValidateTags(tags []cloudformation.Tag) error {
+	docsMessage := "https://docs.aws.amazon.com/AWSCloudFormation/latest/APIReference/API_Tag.html"
+	problems := make([]string, 0)
+
+	if len(tags) > MaxStackTags {
+		problems = append(problems, fmt.Sprintf("%d tags given, at most %d are allowed", len(tags), MaxStackTags))
+	}
+
+	for _, tag := range tags {
+		key, value := "", ""
+
+		if tag.Key != nil {
+			key = *tag.Key
+		}
+
+		if tag.Value != nil {
+			value = *tag.Value
+		}
+
+		switch length := utf8.RuneCountInString(key); {
+		case length == 0:
+			problems = append(problems, "a tag has an empty key")
+		case length > MaxTagKeyLength:
+			problems = append(problems, fmt.Sprintf("tag key %s is %d characters, at most %d are allowed", key, length, MaxTagKeyLength))
+		case strings.HasPrefix(strings.ToLower(key), reservedTagPrefix):
+			problems = append(problems, fmt.Sprintf("tag key %s uses the reserved %s prefix", key, reservedTagPrefix))
+		}
+
+		if length := utf8.RuneCountInString(value); length > MaxTagValueLength {
+			problems = append(problems, fmt.Sprintf("tag %s has a value of %d characters, at most %d are allowed", key, length, MaxTagValueLength))
+		}
+	}
+
+	if len(problems) > 0 {
+		return errors.New(fmt.Sprintf("%s \n %s", colors.Error("Invalid tags:\n  "+strings.Join(problems, "\n  ")), colors.Docs(docsMessage)))
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package data
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// tagCount returns count distinct tags
+func tagCount(count int) []cloudformation.Tag {
+	tags := make([]cloudformation.Tag, 0)
+
+	for i := 0; i < count; i++ {
+		tags = append(tags, cloudformation.Tag{Key: aws.String(fmt.Sprintf("key-%d", i)), Value: aws.String("value")})
+	}
+
+	return tags
+}
+
+func tag(key string, value string) []cloudformation.Tag {
+	return []cloudformation.Tag{{Key: aws.String(key), Value: aws.String(value)}}
+}
+
+func TestValidateTags(t *testing.T) {
+	tests := []struct {
+		name    string
+		tags    []cloudformation.Tag
+		problem string
+	}{
+		{name: "at the tag limit", tags: tagCount(MaxStackTags)},
+		{name: "over the tag limit", tags: tagCount(MaxStackTags + 1), problem: "51 tags given, at most 50 are allowed"},
+		{name: "key at the length limit", tags: tag(strings.Repeat("k", MaxTagKeyLength), "value")},
+		{name: "key over the length limit", tags: tag(strings.Repeat("k", MaxTagKeyLength+1), "value"), problem: "is 129 characters, at most 128 are allowed"},
+		// lengths are counted in characters, not bytes
+		{name: "multibyte key at the length limit", tags: tag(strings.Repeat("é", MaxTagKeyLength), "value")},
+		{name: "value at the length limit", tags: tag("team", strings.Repeat("v", MaxTagValueLength))},
+		{name: "value over the length limit", tags: tag("team", strings.Repeat("v", MaxTagValueLength+1)), problem: "tag team has a value of 257 characters, at most 256 are allowed"},
+		{name: "empty value", tags: tag("team", "")},
+		{name: "empty key", tags: tag("", "value"), problem: "a tag has an empty key"},
+		{name: "reserved prefix", tags: tag("aws:cloudformation:stack-name", "value"), problem: "tag key aws:cloudformation:stack-name uses the reserved aws: prefix"},
+		{name: "reserved prefix in another case", tags: tag("AWS:team", "value"), problem: "uses the reserved aws: prefix"},
+		{name: "prefix without the colon", tags: tag("awsome", "value")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := ValidateTags(test.tags)
+
+			if test.problem == "" {
+				if err != nil {
+					t.Errorf("expected the tags to be valid, got %s", err)
+				}
+
+				return
+			}
+
+			if err == nil || !strings.Contains(err.Error(), test.problem) {
+				t.Errorf("expected an error reporting %q, got %v", test.problem, err)
+			}
+		})
+	}
+}
+
+func TestValidateTagsReportsEveryProblem(t *testing.T) {
+	tags := append(tag("aws:team", "value"), tag("team", strings.Repeat("v", MaxTagValueLength+1))...)
+
+	err := ValidateTags(tags)
+	if err == nil {
+		t.Fatalf("expected the tags to be invalid")
+	}
+
+	for _, problem := range []string{"uses the reserved aws: prefix", "has a value of 257 characters"} {
+		if !strings.Contains(err.Error(), problem) {
+			t.Errorf("expected the error to report %q, got %s", problem, err)
+		}
+	}
+}
+
+func TestTagsDiffer(t *testing.T) {
+	deployed := append(tag("team", "cirrus"), tag("env", "prod")...)
+
+	tests := []struct {
+		name     string
+		local    []cloudformation.Tag
+		expected bool
+	}{
+		{name: "same tags in another order", local: append(tag("env", "prod"), tag("team", "cirrus")...)},
+		{name: "changed value", local: append(tag("team", "cirrus"), tag("env", "dev")...), expected: true},
+		{name: "added tag", local: append(append(tag("team", "cirrus"), tag("env", "prod")...), tag("owner", "ops")...), expected: true},
+		{name: "removed tag", local: tag("team", "cirrus"), expected: true},
+		{name: "no tags", local: nil, expected: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := TagsDiffer(deployed, test.local); got != test.expected {
+				t.Errorf("expected TagsDiffer to be %t, got %t", test.expected, got)
+			}
+		})
+	}
+
+	if TagsDiffer(nil, []cloudformation.Tag{}) {
+		t.Errorf("expected no tags to match no tags")
+	}
+}
@@ -0,0 +1,161 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/colors"
+	"gopkg.in/yaml.v3"
+)
+
+// Template is a partial representation of a CloudFormation template holding the sections cirrus inspects
+type Template struct {
+	Transform  interface{}                  `yaml:"Transform"`
+	Parameters map[string]TemplateParameter `yaml:"Parameters"`
+	Resources  map[string]TemplateResource  `yaml:"Resources"`
+	Outputs    map[string]interface{}       `yaml:"Outputs"`
+}
+
+// TemplateParameter is a parameter declaration in a CloudFormation template
+type TemplateParameter struct {
+	Type           string        `yaml:"Type"`
+	Description    string        `yaml:"Description"`
+	Default        interface{}   `yaml:"Default"`
+	AllowedValues  []interface{} `yaml:"AllowedValues"`
+	AllowedPattern string        `yaml:"AllowedPattern"`
+	MinLength      interface{}   `yaml:"MinLength"`
+	MaxLength      interface{}   `yaml:"MaxLength"`
+	MinValue       interface{}   `yaml:"MinValue"`
+	MaxValue       interface{}   `yaml:"MaxValue"`
+	NoEcho         interface{}   `yaml:"NoEcho"`
+}
+
+// TemplateResource is a resource declaration in a CloudFormation template
+type TemplateResource struct {
+	Type           string                 `yaml:"Type"`
+	Properties     map[string]interface{} `yaml:"Properties"`
+	DependsOn      interface{}            `yaml:"DependsOn"`
+	DeletionPolicy interface{}            `yaml:"DeletionPolicy"`
+}
+
+// TemplateSummary is a structural overview of a CloudFormation template
+type TemplateSummary struct {
+	ResourceCount int                         `json:"resourceCount"`
+	Resources     map[string]int              `json:"resources"`
+	Parameters    []string                    `json:"parameters"`
+	Outputs       []string                    `json:"outputs"`
+	Capabilities  []cloudformation.Capability `json:"capabilities"`
+}
+
+var (
+	//namedIAMProperties are the properties that give an IAM resource a custom name, requiring CAPABILITY_NAMED_IAM
+	namedIAMProperties []string = []string{
+		"GroupName",
+		"InstanceProfileName",
+		"ManagedPolicyName",
+		"RoleName",
+		"UserName",
+	}
+)
+
+// ParseTemplate parses a JSON or YAML CloudFormation template. Short form intrinsic functions (!Ref, !Sub) are accepted
+func ParseTemplate(body []byte) (Template, error) {
+	invalidTemplate := "Unable to parse template. Templates must be valid JSON or YAML"
+	docsMessage := "https://docs.aws.amazon.com/AWSCloudFormation/latest/UserGuide/template-anatomy.html"
+
+	var template Template
+
+	// JSON is a subset of YAML, so a single unmarshaller handles both formats
+	if err := yaml.Unmarshal(body, &template); err != nil {
+		return template, errors.New(fmt.Sprintf("%s \n %s", colors.Error(invalidTemplate), colors.Docs(docsMessage)))
+	}
+
+	return template, nil
+}
+
+// CountResourcesByType counts the resources declared in a template, keyed by resource type
+func CountResourcesByType(template Template) map[string]int {
+	counts := make(map[string]int)
+
+	for _, resource := range template.Resources {
+		counts[resource.Type]++
+	}
+
+	return counts
+}
+
+// GetTemplateParameterKeys returns the sorted keys of all parameters declared in a template
+func GetTemplateParameterKeys(template Template) []string {
+	keys := make([]string, 0)
+
+	for key := range template.Parameters {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// GetTemplateOutputKeys returns the sorted keys of all outputs declared in a template
+func GetTemplateOutputKeys(template Template) []string {
+	keys := make([]string, 0)
+
+	for key := range template.Outputs {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+// GetRequiredCapabilities determines which capabilities a template needs to be deployed, based on its resources and transforms
+func GetRequiredCapabilities(template Template) []cloudformation.Capability {
+	capabilities := make([]cloudformation.Capability, 0)
+	iam, namedIAM, autoExpand := false, false, template.Transform != nil
+
+	for _, resource := range template.Resources {
+		if strings.HasPrefix(resource.Type, "AWS::IAM::") {
+			iam = true
+
+			for _, property := range namedIAMProperties {
+				if _, ok := resource.Properties[property]; ok {
+					namedIAM = true
+				}
+			}
+		}
+
+		if resource.Type == CloudformationStackResource {
+			autoExpand = true
+		}
+	}
+
+	if iam {
+		capabilities = append(capabilities, cloudformation.CapabilityCapabilityIam)
+	}
+
+	if namedIAM {
+		capabilities = append(capabilities, cloudformation.CapabilityCapabilityNamedIam)
+	}
+
+	if autoExpand {
+		capabilities = append(capabilities, cloudformation.CapabilityCapabilityAutoExpand)
+	}
+
+	return capabilities
+}
+
+// SummarizeTemplate builds a structural summary of a template
+func SummarizeTemplate(template Template) TemplateSummary {
+	return TemplateSummary{
+		ResourceCount: len(template.Resources),
+		Resources:     CountResourcesByType(template),
+		Parameters:    GetTemplateParameterKeys(template),
+		Outputs:       GetTemplateOutputKeys(template),
+		Capabilities:  GetRequiredCapabilities(template),
+	}
+}
@@ -0,0 +1,42 @@
+package data
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/blueseph/cirrus/colors"
+)
+
+// NormalizeTemplateURL checks a template URL points at S3 and returns it in the https form CloudFormation accepts.
+// s3://bucket/key is rewritten to https://bucket.s3.amazonaws.com/key, and https URLs must be on an S3 host
+func NormalizeTemplateURL(raw string) (string, error) {
+	docsMessage := "https://docs.aws.amazon.com/AWSCloudFormation/latest/APIReference/API_CreateChangeSet.html"
+	invalid := errors.New(fmt.Sprintf("%s \n %s", colors.Error(fmt.Sprintf("Invalid template URL %s. Expected s3://bucket/key or an https://s3 URL of an S3 object", raw)), colors.Docs(docsMessage)))
+
+	parsed, err := url.Parse(raw)
+	if err != nil || parsed.Host == "" || strings.Trim(parsed.Path, "/") == "" {
+		return "", invalid
+	}
+
+	switch parsed.Scheme {
+	case "s3":
+		return fmt.Sprintf("https://%s.s3.amazonaws.com/%s", parsed.Host, strings.TrimPrefix(parsed.EscapedPath(), "/")), nil
+	case "https":
+		if isS3Host(parsed.Hostname()) {
+			return raw, nil
+		}
+	}
+
+	return "", invalid
+}
+
+// isS3Host determines if a host is an S3 endpoint, path style (s3.region.amazonaws.com) or virtual hosted (bucket.s3.region.amazonaws.com)
+func isS3Host(host string) bool {
+	if !strings.HasSuffix(host, ".amazonaws.com") && !strings.HasSuffix(host, ".amazonaws.com.cn") {
+		return false
+	}
+
+	return strings.HasPrefix(host, "s3.") || strings.HasPrefix(host, "s3-") || strings.Contains(host, ".s3.") || strings.Contains(host, ".s3-")
+}
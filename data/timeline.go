@@ -0,0 +1,101 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/utils"
+)
+
+const (
+	// TimelineFormatMermaid exports a timeline as a Mermaid gantt chart
+	TimelineFormatMermaid string = "mermaid"
+
+	// TimelineFormatJSON exports a timeline as a JSON array of resource timings
+	TimelineFormatJSON string = "json"
+
+	mermaidTimeLayout string = "2006-01-02 15:04:05"
+)
+
+// TimelineEntry is the timing of a single resource during an operation
+type TimelineEntry struct {
+	LogicalResourceID string    `json:"logicalResourceId"`
+	ResourceType      string    `json:"resourceType"`
+	Status            string    `json:"status"`
+	Start             time.Time `json:"start"`
+	End               time.Time `json:"end"`
+}
+
+// Timeline builds the timing of every resource that produced events, ordered by start time
+func Timeline(rows map[string]DisplayRow) []TimelineEntry {
+	entries := make([]TimelineEntry, 0)
+
+	for _, row := range rows {
+		if row.Source != DisplayRowSourceEvent {
+			continue
+		}
+
+		entries = append(entries, TimelineEntry{
+			LogicalResourceID: row.LogicalResourceID,
+			ResourceType:      row.ResourceType,
+			Status:            string(row.Status),
+			Start:             row.StartTimestamp,
+			End:               row.Timestamp,
+		})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].Start.Equal(entries[j].Start) {
+			return entries[i].LogicalResourceID < entries[j].LogicalResourceID
+		}
+
+		return entries[i].Start.Before(entries[j].Start)
+	})
+
+	return entries
+}
+
+// ExportTimeline writes the per-resource start and end times of an operation in a format consumable by gantt renderers (mermaid, json)
+func ExportTimeline(rows map[string]DisplayRow, w io.Writer, format string) error {
+	entries := Timeline(rows)
+
+	switch format {
+	case TimelineFormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+
+		return encoder.Encode(entries)
+	case TimelineFormatMermaid:
+		return writeMermaidGantt(entries, w)
+	}
+
+	return errors.New(colors.Error(fmt.Sprintf("Unknown timeline format %s. Expected mermaid or json", format)))
+}
+
+func writeMermaidGantt(entries []TimelineEntry, w io.Writer) error {
+	chart := "gantt\n"
+	chart += "    dateFormat YYYY-MM-DD HH:mm:ss\n"
+	chart += "    axisFormat %H:%M:%S\n"
+	chart += "    section Resources\n"
+
+	for _, entry := range entries {
+		tag := "done"
+
+		if utils.ContainsResourceStatus(NegativeEventStatus, cloudformation.ResourceStatus(entry.Status)) {
+			tag = "crit"
+		}
+
+		chart += fmt.Sprintf("    %s (%s) :%s, %s, %s\n", entry.LogicalResourceID, entry.Status, tag,
+			entry.Start.UTC().Format(mermaidTimeLayout), entry.End.UTC().Format(mermaidTimeLayout))
+	}
+
+	_, err := io.WriteString(w, chart)
+
+	return err
+}
@@ -1,10 +1,16 @@
 package main
 
 import (
+	"errors"
+	"fmt"
 	"log"
 	"os"
 
+	"github.com/blueseph/cirrus/cfn"
 	"github.com/blueseph/cirrus/cmd"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/utils"
 	"github.com/urfave/cli/v2"
 )
 
@@ -12,16 +18,98 @@ func main() {
 	log.SetFlags(0)
 
 	app := &cli.App{
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "assume-role-arn",
+				Usage: "Assumes the role `arns` before any AWS call. Separate several with commas to assume each in turn with the credentials of the previous one",
+			},
+			&cli.Float64Flag{
+				Name:  "api-rate-limit",
+				Value: cfn.DefaultAPIRateLimit,
+				Usage: "Makes at most `calls` CloudFormation calls per second, shared by everything cirrus watches. 0 removes the limit",
+			},
+			&cli.IntFlag{
+				Name:  "max-retries",
+				Value: cfn.DefaultMaxRetries,
+				Usage: "Retries a throttled AWS call up to `count` times, backing off exponentially with jitter. 0 disables retries",
+			},
+			&cli.DurationFlag{
+				Name:  "timeout",
+				Usage: "Stops waiting after `duration`, e.g. 30m, listing the resources still in progress and exiting with code 124. The operation continues in CloudFormation",
+			},
+			&cli.BoolFlag{
+				Name:  "color",
+				Usage: "Forces colored output, even when stdout is not a terminal or NO_COLOR is set",
+			},
+			&cli.BoolFlag{
+				Name:  "no-color",
+				Usage: "Disables colored output. Also disabled by setting NO_COLOR or when stdout is not a terminal",
+			},
+			&cli.StringSliceFlag{
+				Name:  "theme-color",
+				Usage: "Sets a semantic color as `role=code`, e.g. warning=1;35, over the theme file. Roles are error, docs, status, success, pending, warning and replacement. Repeatable",
+			},
+		},
+		Before: func(c *cli.Context) error {
+			if c.Bool("color") && c.Bool("no-color") {
+				return errors.New(colors.Error("--color and --no-color cannot be used together"))
+			}
+
+			cfn.SetAPIRateLimit(c.Float64("api-rate-limit"))
+			cfn.SetMaxRetries(c.Int("max-retries"))
+			cfn.SetTimeout(c.Duration("timeout"))
+
+			if roles := c.String("assume-role-arn"); roles != "" {
+				cfn.SetAssumeRoleChain(utils.SplitList(roles))
+			}
+
+			colors.SetFlags(c.Bool("color"), c.Bool("no-color"))
+
+			// a broken theme falls back to the default colors rather than stopping the command
+			if err := colors.LoadTheme(colors.ThemePath()); err != nil {
+				fmt.Fprintln(os.Stderr, colors.Status(err.Error()))
+			}
+
+			if err := colors.SetThemeColors(c.StringSlice("theme-color")); err != nil {
+				return errors.New(colors.Error(err.Error()))
+			}
+
+			return nil
+		},
 		Commands: []*cli.Command{
 			cmd.UpCommand,
 			cmd.DownCommand,
+			cmd.AdoptCommand,
+			cmd.ListCommand,
+			cmd.HistoryCommand,
+			cmd.ParamsDiffCommand,
+			cmd.EventsCommand,
+			cmd.DiscoverImportsCommand,
+			cmd.SummaryCommand,
+			cmd.PreflightCommand,
+			cmd.WatchCommand,
 		},
 	}
 
 	app.EnableBashCompletion = true
 
 	err := app.Run(os.Args)
+
+	// a timeout exits with its own code, so CI can tell it from a failed operation
+	var timeoutErr *cfn.TimeoutError
+	if errors.As(err, &timeoutErr) || cfn.IsTimeout(err) {
+		log.Println(data.Redact(err.Error()))
+		os.Exit(cfn.TimeoutExitCode)
+	}
+
+	// differing parameters exit with the code asked for, so a scheduled check can tell drift from an error
+	var differErr *cmd.ParametersDifferError
+	if errors.As(err, &differErr) {
+		log.Println(data.Redact(err.Error()))
+		os.Exit(differErr.ExitCode)
+	}
+
 	if err != nil {
-		log.Fatal(err)
+		log.Fatal(data.Redact(err.Error()))
 	}
 }
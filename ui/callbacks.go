@@ -1,39 +1,65 @@
 package ui
 
 import (
-	"context"
 	"fmt"
 	"time"
 
-	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/blueseph/cirrus/cfn"
-	"github.com/blueseph/cirrus/colors"
 	"github.com/blueseph/cirrus/data"
-	"github.com/blueseph/cirrus/utils"
+	"github.com/gdamore/tcell"
 	"github.com/rivo/tview"
 )
 
-func declineButtonCallbackFn(app *tview.Application, operation cfn.StackOperation) func() {
+func declineButtonCallbackFn(app *tview.Application, operation cfn.StackOperation, outcome chan<- operationOutcome) func() {
 	return func() {
-		declined := "change set"
-
-		if operation == cfn.StackOperationDelete {
-			declined = "delete"
-		}
-
-		defer fmt.Println(colors.Status(fmt.Sprintf("User declined %s", declined)))
+		outcome <- declined(operation)
 		app.Stop()
 	}
 }
 
-func executeButtonCallbackFn(app *tview.Application, displayBox *tview.TextView, form *tview.Form, info data.StackInfo, operation cfn.StackOperation, displayRows map[string]data.DisplayRow, fillDisplayBox func(map[string]data.DisplayRow)) func() {
+func executeButtonCallbackFn(app *tview.Application, displayBox *tview.TextView, form *tview.Form, info data.StackInfo, operation cfn.StackOperation, displayRows map[string]data.DisplayRow, fillDisplayBox func(map[string]data.DisplayRow), options Options, outcome chan<- operationOutcome) func() {
 	return func() {
 		resetForm(app, displayBox, form)
 
 		activatedDisplayRows := activateRowsAndRender(displayRows, fillDisplayBox)
-		executeOperation(operation, info)
 
-		go handleEventsLoop(app, form, info, activatedDisplayRows, fillDisplayBox)
+		since, err := startOperation(operation, info, options)
+		if err != nil {
+			outcome <- aborted(err)
+			app.Stop()
+			return
+		}
+
+		go handleEventsLoop(app, form, info, activatedDisplayRows, fillDisplayBox, since, options, outcome)
+	}
+}
+
+// confirmDeleteCallbackFn replaces the buttons with a field the stack name must be typed in before the deletion starts, as lines
+// output asks for. Anything else, or escape, declines
+func confirmDeleteCallbackFn(app *tview.Application, form *tview.Form, info data.StackInfo, operation cfn.StackOperation, execute func(), outcome chan<- operationOutcome) func() {
+	return func() {
+		form.ClearButtons().SetTitle(" Confirm ")
+		app.SetInputCapture(nil)
+
+		confirmation := tview.NewInputField().
+			SetLabel(fmt.Sprintf("Type the stack name %s to delete it and the resources above: ", info.StackName)).
+			SetFieldWidth(0)
+
+		confirmation.SetDoneFunc(func(key tcell.Key) {
+			if key == tcell.KeyEnter && confirmation.GetText() == info.StackName {
+				form.Clear(false)
+				execute()
+				return
+			}
+
+			if key == tcell.KeyEnter || key == tcell.KeyEscape {
+				outcome <- declined(operation)
+				app.Stop()
+			}
+		})
+
+		form.AddFormItem(confirmation)
+		app.SetFocus(confirmation)
 	}
 }
 
@@ -51,79 +77,11 @@ func activateRowsAndRender(displayRows map[string]data.DisplayRow, fillDisplayBo
 	return activatedDisplayRows
 }
 
-func succeed(app *tview.Application) {
-	defer fmt.Println(colors.Success("Operation Succeeded"))
-	app.Stop()
-}
-
-func fail(app *tview.Application, errors []cloudformation.StackEvent) {
-	errorMsg := colors.Error("Operation failed. The following errors prevented the stack from deploying successfully: \n\n")
-
-	for i, err := range errors {
-		errorMsg += colors.Magenta(*err.LogicalResourceId) + " - " + string(*err.ResourceStatusReason)
-		if i < len(errors)-1 {
-			errorMsg += "\n"
-		}
+func handleEventsLoop(app *tview.Application, form *tview.Form, info data.StackInfo, activatedDisplayRows map[string]data.DisplayRow, fillDisplayBox func(map[string]data.DisplayRow), since time.Time, options Options, outcome chan<- operationOutcome) {
+	notify := func(message string) {
+		addNoticeBar(form, message)
 	}
 
-	defer fmt.Println(errorMsg)
+	outcome <- watchEvents(info, since, activatedDisplayRows, options, fillDisplayBox, notify)
 	app.Stop()
 }
-
-func executeOperation(operation cfn.StackOperation, info data.StackInfo) {
-	var err error
-
-	if operation == cfn.StackOperationDelete {
-		err = cfn.DeleteStack(info)
-	} else {
-		err = cfn.ExecuteChangeSet(info)
-	}
-
-	if err != nil {
-		panic(err)
-	}
-}
-
-func handleEventsLoop(app *tview.Application, form *tview.Form, info data.StackInfo, activatedDisplayRows map[string]data.DisplayRow, fillDisplayBox func(map[string]data.DisplayRow)) {
-	now := time.Now()
-
-	eventIds := make(map[string]bool)
-	errors := make([]cloudformation.StackEvent, 0)
-
-	for {
-		paginator := cfn.GetStackEvents(info)
-
-		for paginator.Next(context.TODO()) {
-			events := paginator.CurrentPage()
-
-			for _, event := range utils.ReverseEvents(events.StackEvents) {
-				if event.Timestamp.After(now) {
-					if *event.ResourceType == data.CloudformationStackResource {
-						if utils.ContainsStackStatus(data.RollbackStackStatus, event.ResourceStatus) {
-							addErrorBar(form)
-						}
-
-						if !utils.ContainsStackStatus(data.PendingStackStatus, event.ResourceStatus) {
-							if len(errors) > 0 {
-								fail(app, errors)
-							} else {
-								succeed(app)
-							}
-						}
-					} else if !eventIds[*event.EventId] {
-						activatedDisplayRows[*event.LogicalResourceId] = data.CreateDisplayRowFromEvent(event)
-
-						if utils.ContainsResourceStatus(data.NegativeEventStatus, event.ResourceStatus) {
-							errors = append(errors, event)
-						}
-
-						eventIds[*event.EventId] = true
-					}
-				}
-			}
-		}
-
-		fillDisplayBox(activatedDisplayRows)
-		time.Sleep(500 * time.Millisecond)
-	}
-}
@@ -0,0 +1,44 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+)
+
+// printCompact prints one terse line per resource, `STATUS LogicalID (Type)`, sorted by status then logical ID
+func printCompact(displayRows map[string]data.DisplayRow, options Options) {
+	keys := sortedKeys(displayRows)
+
+	sort.SliceStable(keys, func(i, j int) bool {
+		return compactStatus(displayRows[keys[i]]) < compactStatus(displayRows[keys[j]])
+	})
+
+	for _, key := range keys {
+		fmt.Println(formatCompactLine(displayRows[key], options))
+	}
+}
+
+func formatCompactLine(row data.DisplayRow, options Options) string {
+	status := compactStatus(row)
+
+	if row.Source == data.DisplayRowSourceEvent {
+		status = colorizeStatusANSI(row.Status)
+	} else {
+		status = colors.Yellow(status)
+	}
+
+	return nestingIndent(row) + fmt.Sprintf("%s %s (%s)", status, colors.Teal(row.LogicalResourceID), options.displayType(row.ResourceType))
+}
+
+// compactStatus is the last event status of a row, or the change it was waiting on if it never produced an event
+func compactStatus(row data.DisplayRow) string {
+	if row.Source == data.DisplayRowSourceEvent {
+		return string(row.Status)
+	}
+
+	return "PENDING_" + strings.ToUpper(string(row.Action))
+}
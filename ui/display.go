@@ -1,10 +1,13 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
 	"github.com/blueseph/cirrus/data"
 	"github.com/gdamore/tcell"
 	"github.com/rivo/tview"
@@ -16,21 +19,70 @@ var (
 )
 
 //DisplayChanges shows the change set in a graphic interface and waits for response. Cancels the command if the user declines, or executes and tails the events log
-func DisplayChanges(info data.StackInfo, changeSet *cloudformation.DescribeChangeSetResponse, operation cfn.StackOperation) error {
+func DisplayChanges(info data.StackInfo, changeSet *cloudformation.DescribeChangeSetResponse, operation cfn.StackOperation, options Options) (data.DeployResult, error) {
 	displayRows := data.ChangeMap(changeSet.Changes, false)
+	data.ApplyPropertyValues(displayRows, options.PropertyValues)
 
-	err := showScreen(displayRows, operation, info)
-
-	return err
+	return show(displayRows, operation, info, options)
 }
 
-//DisplayDeletes shows the stack resoures and tails the events log.
-func DisplayDeletes(info data.StackInfo, resources []cloudformation.StackResourceSummary) error {
+//DisplayDeletes shows the stack resoures and tails the events log. Resources are annotated with their deletion step and policy
+//from the deployed template, which may be empty when it couldn't be read
+func DisplayDeletes(info data.StackInfo, resources []cloudformation.StackResourceSummary, template data.Template, options Options) (data.DeployResult, error) {
 	displayRows := data.ResourceMap(resources)
+	data.AnnotateDeletions(displayRows, template)
+
+	return show(displayRows, cfn.StackOperationDelete, info, options)
+}
+
+func show(displayRows map[string]data.DisplayRow, operation cfn.StackOperation, info data.StackInfo, options Options) (data.DeployResult, error) {
+	var result operationOutcome
+
+	switch options.Output {
+	case OutputTable:
+		result = showScreen(displayRows, operation, info, options)
+	case OutputLines, OutputCompact, OutputGitHub, OutputSummaryTable:
+		result = showLines(displayRows, operation, info, options)
+	case OutputJSON:
+		result = showJSON(displayRows, operation, info, options)
+	default:
+		return data.DeployResult{}, errors.New(colors.Error(fmt.Sprintf("Unknown output format %s. Expected table, lines, compact, github, summary-table, or json", options.Output)))
+	}
+
+	if options.Output == OutputCompact && len(result.rows) > 0 {
+		printCompact(result.rows, options)
+	}
 
-	err := showScreen(displayRows, cfn.StackOperationDelete, info)
+	if options.Output == OutputSummaryTable && len(result.rows) > 0 {
+		printReconciliation(data.Reconcile(displayRows, result.rows))
+	}
+
+	if options.Output == OutputGitHub {
+		printAnnotations(result)
+
+		if err := writeStepSummary(info, result); err != nil && result.err == nil {
+			result.err = err
+		}
+	}
+
+	if options.Output == OutputJSON {
+		// stdout only carries the rows, so jq can read it
+		if result.message != "" {
+			fmt.Fprintln(os.Stderr, result.message)
+		}
 
-	return err
+		if err := printJSON(result.rows); err != nil && result.err == nil {
+			result.err = err
+		}
+
+		return toDeployResult(info, result), result.err
+	}
+
+	if result.message != "" {
+		fmt.Println(result.message)
+	}
+
+	return toDeployResult(info, result), result.err
 }
 
 func createTitleBar(info data.StackInfo, operation cfn.StackOperation) *tview.TextView {
@@ -54,40 +106,49 @@ func createDisplayRowBox(app *tview.Application) *tview.TextView {
 	return textView
 }
 
-func fillDisplayBoxFn(displayBox *tview.TextView) func(map[string]data.DisplayRow) {
+func fillDisplayBoxFn(displayBox *tview.TextView, options Options) func(map[string]data.DisplayRow) {
 	return func(displayRows map[string]data.DisplayRow) {
-		displayBox.SetText(ParseDisplayRows(displayRows))
+		displayBox.SetText(ParseDisplayRows(displayRows, options))
+		displayBox.SetTitle(changesTitle(displayRows))
 	}
 }
 
-func createActionBar(app *tview.Application, displayBox *tview.TextView, info data.StackInfo, operation cfn.StackOperation, displayRows map[string]data.DisplayRow, fillDisplayBox func(map[string]data.DisplayRow)) *tview.Form {
+func createActionBar(app *tview.Application, displayBox *tview.TextView, info data.StackInfo, operation cfn.StackOperation, displayRows map[string]data.DisplayRow, fillDisplayBox func(map[string]data.DisplayRow), options Options, outcome chan<- operationOutcome) *tview.Form {
 	form := tview.NewForm()
 
+	execute := executeButtonCallbackFn(app, displayBox, form, info, operation, displayRows, fillDisplayBox, options, outcome)
+	if operation == cfn.StackOperationDelete && !options.AutoApprove {
+		execute = confirmDeleteCallbackFn(app, form, info, operation, execute, outcome)
+	}
+
 	form.
-		AddButton(executeButtonLabel, executeButtonCallbackFn(app, displayBox, form, info, operation, displayRows, fillDisplayBox)).
-		AddButton(declineButtonLabel, declineButtonCallbackFn(app, operation))
+		AddButton(executeButtonLabel, execute).
+		AddButton(declineButtonLabel, declineButtonCallbackFn(app, operation, outcome))
 
 	form.SetButtonsAlign(tview.AlignCenter).SetBorder(true).SetTitle(" Actions ")
 
 	return form
 }
 
-func addErrorBar(form *tview.Form) {
-	errorBar := tview.NewInputField().
-		SetLabel("Operation failed. View failure log after rollback completes").
+func addNoticeBar(form *tview.Form, message string) {
+	noticeBar := tview.NewInputField().
+		SetLabel(message).
 		SetFieldWidth(-1)
 
-	form.AddFormItem(errorBar)
+	form.AddFormItem(noticeBar)
 }
 
-func showScreen(displayRows map[string]data.DisplayRow, operation cfn.StackOperation, info data.StackInfo) error {
+func showScreen(displayRows map[string]data.DisplayRow, operation cfn.StackOperation, info data.StackInfo, options Options) operationOutcome {
 	app := tview.NewApplication()
 
+	// reports how the operation finished, so it can be printed once the app releases the terminal
+	outcome := make(chan operationOutcome, 1)
+
 	displayBox := createDisplayRowBox(app)
-	fillDisplayBox := fillDisplayBoxFn(displayBox)
+	fillDisplayBox := fillDisplayBoxFn(displayBox, options)
 
 	titleBar := createTitleBar(info, operation)
-	actionBar := createActionBar(app, displayBox, info, operation, displayRows, fillDisplayBox)
+	actionBar := createActionBar(app, displayBox, info, operation, displayRows, fillDisplayBox, options, outcome)
 
 	fillDisplayBox(displayRows)
 
@@ -102,11 +163,25 @@ func showScreen(displayRows map[string]data.DisplayRow, operation cfn.StackOpera
 	appSetInputCapture := appSetInputCaptureFn(view)
 	app.SetInputCapture(appSetInputCapture)
 
+	stopRedrawOnResize := redrawOnResize(app)
+	defer stopRedrawOnResize()
+
+	// approved operations start as soon as the app runs, without waiting for the execute button
+	if options.AutoApprove {
+		go app.QueueUpdateDraw(executeButtonCallbackFn(app, displayBox, actionBar, info, operation, displayRows, fillDisplayBox, options, outcome))
+	}
+
 	if err := app.SetRoot(view, true).SetFocus(displayBox).Run(); err != nil {
 		panic(err)
 	}
 
-	return nil
+	select {
+	case result := <-outcome:
+		return result
+	default:
+		// the app was stopped by the user before the operation finished
+		return operationOutcome{}
+	}
 }
 
 //hacky workaround
@@ -1,13 +1,16 @@
 package ui
 
 import (
+	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
 	"github.com/blueseph/cirrus/data"
 	"github.com/blueseph/cirrus/utils"
+	"github.com/rivo/tview"
 )
 
 func stackOperationColorize(operation cfn.StackOperation) string {
@@ -37,6 +40,10 @@ func colorizeAction(change cloudformation.ChangeAction, ascii bool) string {
 		color = "[red::b]"
 	}
 
+	if change == cloudformation.ChangeActionImport {
+		color = "[blue::b]"
+	}
+
 	if ascii {
 		return color + cfn.ChangeSetASCII[change] + end
 	}
@@ -44,23 +51,39 @@ func colorizeAction(change cloudformation.ChangeAction, ascii bool) string {
 	return color + strings.ToUpper(string(change)) + end
 }
 
-func colorizeResourceStatus(status cloudformation.ResourceStatus) string {
-	color := "[green::b]"
-	end := "[-]"
-
-	if utils.ContainsResourceStatus(data.PendingEventStatus, status) {
-		color = "[yellow::b]"
+// changeGlyph is the glyph of a change, which for a replacement stands out from an in-place modification: red when the resource
+// will be replaced and yellow when it may be
+func changeGlyph(row data.DisplayRow) string {
+	switch row.Replacement {
+	case cloudformation.ReplacementTrue:
+		return "[" + colors.TviewColor("replacement") + "::b]⇄ [-]"
+	case cloudformation.ReplacementConditional:
+		return "[" + colors.TviewColor("warning") + "::b]⇄?[-]"
 	}
 
-	if utils.ContainsResourceStatus(data.NegativeEventStatus, status) {
-		color = "[red::b]"
+	return colorizeAction(row.Action, true)
+}
+
+// statusTone is the color name of a resource status, from the event status lists in data. Statuses in none of them stay white
+func statusTone(status cloudformation.ResourceStatus) string {
+	switch {
+	case utils.ContainsResourceStatus(data.NegativeEventStatus, status):
+		return "red"
+	case utils.ContainsResourceStatus(data.PendingEventStatus, status):
+		return "yellow"
+	case utils.ContainsResourceStatus(data.PositiveEventStatus, status):
+		return "green"
 	}
 
-	return color + strings.ToUpper(string(status)) + end
+	return "white"
 }
 
-func resourceTypeFormat(resourceType string) string {
-	replaced := strings.ReplaceAll(resourceType, "::", ".")
+func colorizeResourceStatus(status cloudformation.ResourceStatus) string {
+	return "[" + statusTone(status) + "::b]" + strings.ToUpper(string(status)) + "[-]"
+}
+
+func resourceTypeFormat(resourceType string, options Options) string {
+	replaced := strings.ReplaceAll(options.displayType(resourceType), "::", ".")
 	lowered := strings.ToLower(replaced)
 
 	return "[grey::d]" + lowered + "[-]"
@@ -78,57 +101,140 @@ func getTitleBar(info data.StackInfo, operation cfn.StackOperation) string {
 	return title
 }
 
-func parseDisplayRow(row data.DisplayRow) string {
+func progressBar(percent float64) string {
+	width := 20
+	filled := int(percent / 100 * float64(width))
+
+	return "[green]" + strings.Repeat("█", filled) + "[grey]" + strings.Repeat("░", width-filled) + "[-]"
+}
+
+func changesTitle(displayRows map[string]data.DisplayRow) string {
+	if !data.IsOperationStarted(displayRows) {
+		summary, risk := data.SummarizeRisk(displayRows)
+		if summary == "" {
+			return " Changes "
+		}
+
+		return " Changes " + riskColor(risk) + summary + "[-] "
+	}
+
+	percent := data.ProgressPercent(displayRows)
+
+	return fmt.Sprintf(" Changes %s ~%.0f%% (approx.) ", progressBar(percent), percent)
+}
+
+// riskColor is the tview color tag of a risk level
+func riskColor(risk data.RiskLevel) string {
+	switch risk {
+	case data.RiskDeletion, data.RiskReplacement:
+		return "[red::b]"
+	case data.RiskModify:
+		return "[yellow::b]"
+	}
+
+	return "[green::b]"
+}
+
+func parseDisplayRow(row data.DisplayRow, options Options) string {
 	if row.Source == data.DisplayRowSourceEvent {
-		return parseEventRow(row)
+		return parseEventRow(row, options)
 	}
 
-	return parseRow(row)
+	return parseRow(row, options)
 }
 
-func parseRow(row data.DisplayRow) string {
+func parseRow(row data.DisplayRow, options Options) string {
 	var formatted string
 	replacement := row.Replacement
 
 	if row.Active {
 		formatted += "[[grey]PENDING_" + strings.ToUpper(string(row.Action)) + "[-]] "
 	} else {
-		formatted += "[" + colorizeAction(row.Action, true) + "] "
+		formatted += "[" + changeGlyph(row) + "] "
 	}
 
 	formatted += "[#00b8ea]" + row.LogicalResourceID + " [white]"
 	if !row.Active {
 		formatted += colorizeAction(row.Action, false) + " "
 	}
-	formatted += resourceTypeFormat(row.ResourceType)
+	formatted += resourceTypeFormat(row.ResourceType, options)
 
 	if !row.Active {
 		if replacement == cloudformation.ReplacementTrue {
-			formatted += " [red]Replace[white]"
+			formatted += " [" + colors.TviewColor("replacement") + "]Replace" + replacedByFormat(row.ReplacedBy) + "[white]"
 		}
 
 		if replacement == cloudformation.ReplacementConditional {
-			formatted += " [yellow]Replace conditional[white]"
+			formatted += " [" + colors.TviewColor("warning") + "]Replace conditional" + replacedByFormat(row.ReplacedBy) + "[white]"
+		}
+
+		if row.DeletionStep > 0 {
+			formatted += fmt.Sprintf(" [grey]step %d[white]", row.DeletionStep)
+		}
+
+		if data.IsRetainedOnDelete(row.DeletionPolicy) {
+			formatted += " [magenta::b]" + retainedLabel(row.DeletionPolicy) + "[-]"
+		}
+
+		for _, line := range detailLines(row, options) {
+			formatted += "\n    [grey]" + tview.Escape(line) + "[white]"
 		}
 	}
 
 	return formatted + "\n"
 }
 
-func parseEventRow(row data.DisplayRow) string {
-	var formatted string
+// retainedLabel marks a resource its deletion policy keeps in the account when the stack is deleted
+// detailLines are the lines shown below a change: the source of each change when verbose, and the values of each changed
+// property when they were described, once per property
+func detailLines(row data.DisplayRow, options Options) []string {
+	lines := make([]string, 0)
+	described := make(map[string]bool)
+
+	for _, detail := range row.Details {
+		if options.VerboseChanges {
+			lines = append(lines, data.DescribeChangeDetail(detail))
+		}
+
+		if values := data.DescribeChangeValues(detail); detail.HasValues() && !described[values] {
+			described[values] = true
+			lines = append(lines, values)
+		}
+	}
+
+	return lines
+}
+
+func retainedLabel(policy string) string {
+	return "Retained (DeletionPolicy " + policy + "), not deleted"
+}
+
+// replacedByFormat lists the properties causing a replacement, e.g. " (InstanceType, SubnetId)"
+func replacedByFormat(properties []string) string {
+	if len(properties) == 0 {
+		return ""
+	}
+
+	return " (" + strings.Join(properties, ", ") + ")"
+}
+
+func parseEventRow(row data.DisplayRow, options Options) string {
+	formatted := nestingIndent(row)
 
 	formatted += "[" + colorizeResourceStatus(row.Status) + "]"
-	formatted += "[#00b8ea]" + row.LogicalResourceID + " [white]"
-	formatted += resourceTypeFormat(row.ResourceType)
+	formatted += "[" + statusTone(row.Status) + "]" + row.LogicalResourceID + " [white]"
+	formatted += resourceTypeFormat(row.ResourceType, options)
 
 	return formatted + "\n"
 }
 
-//ParseDisplayRows parses and sorts the map of display rows and returns a tview.TextBox consumable string
-func ParseDisplayRows(displayRows map[string]data.DisplayRow) string {
+// nestingIndent indents the rows of nested stack resources below the row of their nested stack
+func nestingIndent(row data.DisplayRow) string {
+	return strings.Repeat("  ", row.Depth())
+}
+
+func sortedKeys(displayRows map[string]data.DisplayRow) []string {
 	keys := make([]string, 0)
-	var allChanges string
 
 	for key := range displayRows {
 		keys = append(keys, key)
@@ -136,8 +242,15 @@ func ParseDisplayRows(displayRows map[string]data.DisplayRow) string {
 
 	sort.Strings(keys)
 
-	for _, key := range keys {
-		msg := parseDisplayRow(displayRows[key])
+	return keys
+}
+
+//ParseDisplayRows parses and sorts the map of display rows and returns a tview.TextBox consumable string
+func ParseDisplayRows(displayRows map[string]data.DisplayRow, options Options) string {
+	var allChanges string
+
+	for _, key := range sortedKeys(displayRows) {
+		msg := parseDisplayRow(displayRows[key], options)
 		allChanges += msg
 	}
 	return allChanges
@@ -0,0 +1,87 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/blueseph/cirrus/data"
+)
+
+// IsGitHubActions determines if cirrus is running in a GitHub Actions step
+func IsGitHubActions() bool {
+	return os.Getenv("GITHUB_ACTIONS") == "true"
+}
+
+// printAnnotations emits an ::error workflow command per failed resource, so GitHub Actions shows the failures on the run.
+// An operation that failed without resource failures is annotated with its error
+func printAnnotations(result operationOutcome) {
+	for _, failure := range result.failures {
+		title := fmt.Sprintf("%s (%s) %s", *failure.LogicalResourceId, *failure.ResourceType, failure.ResourceStatus)
+		fmt.Printf("::error title=%s::%s\n", escapeWorkflowProperty(title), escapeWorkflowData(statusReason(failure)))
+	}
+
+	if len(result.failures) == 0 && result.err != nil {
+		fmt.Printf("::error title=cirrus::%s\n", escapeWorkflowData(data.Redact(result.err.Error())))
+	}
+}
+
+// writeStepSummary appends a markdown table of the resources of the operation to the job summary. Nothing is written outside
+// of GitHub Actions, where GITHUB_STEP_SUMMARY isn't set
+func writeStepSummary(info data.StackInfo, result operationOutcome) error {
+	location := os.Getenv("GITHUB_STEP_SUMMARY")
+	if location == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(location, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+
+	defer file.Close()
+
+	_, err = file.WriteString(stepSummary(info, result))
+
+	return err
+}
+
+func stepSummary(info data.StackInfo, result operationOutcome) string {
+	status := string(result.status)
+	if status == "" {
+		status = "not executed"
+	}
+
+	summary := fmt.Sprintf("### %s: %s\n\n", info.StackName, status)
+
+	if result.rootCause != nil {
+		summary += fmt.Sprintf("Root cause: **%s** - %s\n\n", *result.rootCause.LogicalResourceId, markdownCell(statusReason(*result.rootCause)))
+	}
+
+	if len(result.rows) == 0 {
+		return summary
+	}
+
+	summary += "| Resource | Type | Status |\n| --- | --- | --- |\n"
+
+	for _, key := range sortedKeys(result.rows) {
+		row := result.rows[key]
+		summary += fmt.Sprintf("| %s | %s | %s |\n", row.LogicalResourceID, row.ResourceType, compactStatus(row))
+	}
+
+	return summary + "\n"
+}
+
+// escapeWorkflowData escapes the message of a workflow command, which ends at a newline
+func escapeWorkflowData(message string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(message)
+}
+
+// escapeWorkflowProperty escapes a property of a workflow command, which also ends at a colon or comma
+func escapeWorkflowProperty(property string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(escapeWorkflowData(property))
+}
+
+func markdownCell(text string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(text)
+}
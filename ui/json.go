@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+)
+
+// showJSON executes an operation without printing anything to stdout until it finishes, since it can't ask for confirmation
+// on the stream it writes JSON to. Notices go to stderr
+func showJSON(displayRows map[string]data.DisplayRow, operation cfn.StackOperation, info data.StackInfo, options Options) operationOutcome {
+	if !options.AutoApprove {
+		return aborted(errors.New(colors.Error(fmt.Sprintf("Output json can't ask to confirm the %s. Pass --yes to execute it", operation))))
+	}
+
+	activatedDisplayRows := data.ActivateDisplayRows(displayRows)
+
+	since, err := startOperation(operation, info, options)
+	if err != nil {
+		return aborted(err)
+	}
+
+	return watchEvents(info, since, activatedDisplayRows, options, func(map[string]data.DisplayRow) {}, func(message string) {
+		fmt.Fprintln(os.Stderr, colors.Status(message))
+	})
+}
+
+// printJSON prints the rows as a JSON object keyed by logical ID. Nothing is printed when the operation never produced rows
+func printJSON(displayRows map[string]data.DisplayRow) error {
+	if displayRows == nil {
+		return nil
+	}
+
+	encoded, err := json.MarshalIndent(displayRows, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(string(encoded))
+
+	return nil
+}
@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/utils"
+)
+
+// showLines renders an operation as append-only lines without cursor movement, so output that isn't a terminal stays readable
+func showLines(displayRows map[string]data.DisplayRow, operation cfn.StackOperation, info data.StackInfo, options Options) operationOutcome {
+	printPreview(displayRows, operation, info, options)
+
+	if !options.AutoApprove {
+		confirm, err := confirmOperation(operation, info)
+		if err != nil {
+			return aborted(err)
+		}
+
+		if !confirm {
+			return declined(operation)
+		}
+	}
+
+	activatedDisplayRows := data.ActivateDisplayRows(displayRows)
+
+	since, err := startOperation(operation, info, options)
+	if err != nil {
+		return aborted(err)
+	}
+
+	render := linesRenderer(activatedDisplayRows, options)
+	if options.Output == OutputCompact {
+		render = func(map[string]data.DisplayRow) {}
+	}
+
+	return watchEvents(info, since, activatedDisplayRows, options, render, printNoticeLine)
+}
+
+// PrintDeletes prints the resources a deletion would remove in their estimated deletion order, marking those that are retained,
+// without deleting anything
+func PrintDeletes(info data.StackInfo, resources []cloudformation.StackResourceSummary, template data.Template, options Options) {
+	displayRows := data.ResourceMap(resources)
+	data.AnnotateDeletions(displayRows, template)
+
+	fmt.Println(getLinesTitle(info, cfn.StackOperationDelete))
+
+	keys := sortedKeys(displayRows)
+	sort.SliceStable(keys, func(i, j int) bool {
+		return displayRows[keys[i]].DeletionStep < displayRows[keys[j]].DeletionStep
+	})
+
+	retained := 0
+
+	for _, key := range keys {
+		fmt.Println(formatLine(displayRows[key], options))
+
+		if data.IsRetainedOnDelete(displayRows[key].DeletionPolicy) {
+			retained++
+		}
+	}
+
+	fmt.Println(colors.Status(fmt.Sprintf("%d resources, %d retained. Steps are estimated from the template's dependencies", len(keys), retained)))
+}
+
+// PrintChanges prints the preview of a change set as lines without executing it
+func PrintChanges(info data.StackInfo, changeSet *cloudformation.DescribeChangeSetResponse, operation cfn.StackOperation, options Options) {
+	displayRows := data.ChangeMap(changeSet.Changes, false)
+	data.ApplyPropertyValues(displayRows, options.PropertyValues)
+
+	printPreview(displayRows, operation, info, options)
+}
+
+// printPreview prints the title, the risk summary and a line per change, with the details of each when verbose
+func printPreview(displayRows map[string]data.DisplayRow, operation cfn.StackOperation, info data.StackInfo, options Options) {
+	fmt.Println(getLinesTitle(info, operation))
+
+	if summary, risk := data.SummarizeRisk(displayRows); summary != "" {
+		fmt.Println(riskLine(summary, risk))
+	}
+
+	for _, key := range sortedKeys(displayRows) {
+		fmt.Println(formatLine(displayRows[key], options))
+
+		for _, line := range detailLines(displayRows[key], options) {
+			fmt.Println("    " + line)
+		}
+	}
+}
+
+// confirmOperation asks whether to execute the operation. Deleting a stack requires typing its name, and fails rather than
+// waiting for input that can't come when stdin isn't a terminal
+func confirmOperation(operation cfn.StackOperation, info data.StackInfo) (bool, error) {
+	if operation != cfn.StackOperationDelete {
+		return utils.AskYesNoQuestion(colors.Status(fmt.Sprintf("Execute %s? [Y/N]", operation)))
+	}
+
+	if !utils.IsTerminal(os.Stdin) {
+		return false, errors.New(colors.Error(fmt.Sprintf("Refusing to delete %s without confirmation, since stdin is not a terminal. Pass --yes to delete without asking", info.StackName)))
+	}
+
+	answer, err := utils.AskForText(colors.Status(fmt.Sprintf("Type the stack name %s to delete it and the resources above:", info.StackName)))
+	if err != nil {
+		return false, err
+	}
+
+	return answer == info.StackName, nil
+}
+
+// WatchStack prints the events of an operation already in progress as append-only lines until the stack reaches a terminal status.
+// Events at or before since are skipped
+func WatchStack(info data.StackInfo, since time.Time, options Options) (data.DeployResult, error) {
+	fmt.Println(colors.Status("WATCH " + info.StackName))
+	fmt.Println(colors.Status("Id: " + info.StackID))
+
+	displayRows := make(map[string]data.DisplayRow)
+
+	result := watchEvents(info, since, displayRows, options, linesRenderer(displayRows, options), printNoticeLine)
+
+	if result.message != "" {
+		fmt.Println(result.message)
+	}
+
+	return toDeployResult(info, result), result.err
+}
+
+// linesRenderer returns a render function that prints only the rows that changed since the last render
+func linesRenderer(displayRows map[string]data.DisplayRow, options Options) func(map[string]data.DisplayRow) {
+	printed := data.CopyDisplayRows(displayRows)
+
+	return func(rows map[string]data.DisplayRow) {
+		for _, key := range data.DiffRowMaps(printed, rows) {
+			if row, ok := rows[key]; ok {
+				fmt.Println(formatLine(row, options))
+			}
+		}
+
+		printed = data.CopyDisplayRows(rows)
+	}
+}
+
+// riskLine tints the risk summary by its highest risk level
+func riskLine(summary string, risk data.RiskLevel) string {
+	switch risk {
+	case data.RiskDeletion, data.RiskReplacement:
+		return colors.Red(summary)
+	case data.RiskModify:
+		return colors.Yellow(summary)
+	}
+
+	return colors.Green(summary)
+}
+
+func printNoticeLine(message string) {
+	if message == rollbackNotice {
+		fmt.Println(colors.Error(message))
+		return
+	}
+
+	fmt.Println(colors.Status(message))
+}
+
+func getLinesTitle(info data.StackInfo, operation cfn.StackOperation) string {
+	title := colors.Status(strings.ToUpper(string(operation)) + " " + info.StackName)
+
+	if info.StackID != "" {
+		title += "\n" + colors.Status("Id: "+info.StackID)
+	}
+
+	if operation != cfn.StackOperationDelete {
+		title += "\n" + colors.Status("Changeset: "+info.ChangeSetName)
+	}
+
+	return title
+}
+
+func formatLine(row data.DisplayRow, options Options) string {
+	resourceType := strings.ToLower(strings.ReplaceAll(options.displayType(row.ResourceType), "::", "."))
+
+	if row.Source == data.DisplayRowSourceEvent {
+		// lines of nested stacks are interleaved with the others, so they're named by their full path instead of indented
+		name := data.NestedRowKey(row.Parent, row.LogicalResourceID)
+		line := fmt.Sprintf("[%s] %s %s", colorizeStatusANSI(row.Status), colors.Teal(name), resourceType)
+
+		// the reason is what explains a failure, and is noise for every other status
+		if row.StatusReason != "" && statusTone(row.Status) == "red" {
+			line += " - " + row.StatusReason
+		}
+
+		return line
+	}
+
+	line := fmt.Sprintf("[%s] %s %s", colorizeActionANSI(row.Action), colors.Teal(row.LogicalResourceID), resourceType)
+
+	if row.Replacement == cloudformation.ReplacementTrue {
+		line += " " + colors.Replacement("Replace"+replacedByFormat(row.ReplacedBy))
+	}
+
+	if row.Replacement == cloudformation.ReplacementConditional {
+		line += " " + colors.Warning("Replace conditional"+replacedByFormat(row.ReplacedBy))
+	}
+
+	if row.DeletionStep > 0 {
+		line += " " + fmt.Sprintf("step %d", row.DeletionStep)
+	}
+
+	if data.IsRetainedOnDelete(row.DeletionPolicy) {
+		line += " " + colors.Magenta(retainedLabel(row.DeletionPolicy))
+	}
+
+	return line
+}
+
+func colorizeStatusANSI(status cloudformation.ResourceStatus) string {
+	switch statusTone(status) {
+	case "red":
+		return colors.Red(status)
+	case "yellow":
+		return colors.Yellow(status)
+	case "green":
+		return colors.Green(status)
+	}
+
+	return string(status)
+}
+
+func colorizeActionANSI(action cloudformation.ChangeAction) string {
+	label := strings.ToUpper(string(action))
+
+	switch action {
+	case cloudformation.ChangeActionModify:
+		return colors.Yellow(label)
+	case cloudformation.ChangeActionRemove:
+		return colors.Red(label)
+	case cloudformation.ChangeActionImport:
+		return colors.Purple(label)
+	}
+
+	return colors.Green(label)
+}
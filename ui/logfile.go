@@ -0,0 +1,73 @@
+package ui
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/blueseph/cirrus/data"
+)
+
+// loggedRow is a line of the row log, the row with the stack it belongs to
+type loggedRow struct {
+	Stack string `json:"stack"`
+	data.DisplayRow
+}
+
+// rowLog appends every version of every row observed during a watch to a file as newline-delimited JSON, regardless of how
+// the watch is rendered
+type rowLog struct {
+	file    *os.File
+	stack   string
+	written map[string]data.DisplayRow
+}
+
+// openRowLog opens the log file for appending, so the watches of several operations accumulate in one file. Without a
+// location, the log writes nothing
+func openRowLog(location string, info data.StackInfo) (*rowLog, error) {
+	log := &rowLog{stack: info.StackName, written: make(map[string]data.DisplayRow)}
+
+	if location == "" {
+		return log, nil
+	}
+
+	file, err := os.OpenFile(location, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	log.file = file
+
+	return log, nil
+}
+
+// write appends the rows that changed since the last write, sorted by key
+func (log *rowLog) write(rows map[string]data.DisplayRow) error {
+	if log.file == nil {
+		return nil
+	}
+
+	encoder := json.NewEncoder(log.file)
+
+	for _, key := range data.DiffRowMaps(log.written, rows) {
+		row, ok := rows[key]
+		if !ok {
+			continue
+		}
+
+		if err := encoder.Encode(loggedRow{Stack: log.stack, DisplayRow: row}); err != nil {
+			return err
+		}
+	}
+
+	log.written = data.CopyDisplayRows(rows)
+
+	return nil
+}
+
+func (log *rowLog) close() error {
+	if log.file == nil {
+		return nil
+	}
+
+	return log.file.Close()
+}
@@ -0,0 +1,294 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/utils"
+	"github.com/rivo/tview"
+)
+
+// multiPollInterval spaces the event requests of all watched stacks together, so watching more stacks doesn't multiply API calls
+const multiPollInterval = 250 * time.Millisecond
+
+// StackWatch is a stack to watch alongside others, with its status when the watch starts and the cutoff for events of its operation
+type StackWatch struct {
+	Info   data.StackInfo
+	Status cloudformation.StackStatus
+	Since  time.Time
+}
+
+// stackSection is the state of one stack in a multi-stack watch
+type stackSection struct {
+	index  int
+	info   data.StackInfo
+	status cloudformation.ResourceStatus
+	rows   map[string]data.DisplayRow
+	err    error
+}
+
+func (section stackSection) finished() bool {
+	return section.err != nil || !utils.ContainsStackStatus(data.PendingStackStatus, section.status)
+}
+
+func (section stackSection) failed() bool {
+	return section.err != nil || utils.ContainsStackStatus(data.NegativeStackStatus, section.status)
+}
+
+// WatchStacks follows several stacks at once, in a section per stack with its own rows and overall status, until every stack
+// reaches a terminal status. Table output redraws the sections together and lines output prefixes each line with its stack.
+// It fails if any stack failed, or if the user quit before every stack finished
+func WatchStacks(watches []StackWatch, options Options) error {
+	limiter := time.NewTicker(multiPollInterval)
+	defer limiter.Stop()
+
+	updates := make(chan stackSection)
+
+	for i, watch := range watches {
+		go pollSection(i, watch, limiter.C, options, updates)
+	}
+
+	var sections []stackSection
+
+	switch options.Output {
+	case OutputTable:
+		sections = showSections(watches, options, updates)
+	case OutputLines, OutputGitHub:
+		sections = printSections(watches, options, updates)
+	default:
+		return errors.New(colors.Error(fmt.Sprintf("Unknown output format %s. Expected table or lines", options.Output)))
+	}
+
+	failures := make([]string, 0)
+	unfinished := make([]string, 0)
+	pending := make([]string, 0)
+	timedOut := false
+
+	for _, section := range sections {
+		fmt.Println(sectionSummary(section))
+
+		if cfn.IsTimeout(section.err) {
+			timedOut = true
+
+			for _, resource := range data.PendingResources(section.rows) {
+				pending = append(pending, section.info.StackName+"/"+resource)
+			}
+
+			continue
+		}
+
+		if section.failed() {
+			failures = append(failures, section.info.StackName)
+		}
+
+		if !section.finished() {
+			unfinished = append(unfinished, section.info.StackName)
+		}
+	}
+
+	if len(failures) > 0 {
+		return errors.New(colors.Error("Stacks failed: " + strings.Join(failures, ", ")))
+	}
+
+	if len(unfinished) > 0 {
+		return errors.New(colors.Error("Stopped watching before these stacks finished: " + strings.Join(unfinished, ", ")))
+	}
+
+	if timedOut {
+		return &cfn.TimeoutError{Pending: pending}
+	}
+
+	return nil
+}
+
+// pollSection sends the state of a stack after every poll until it finishes. Each poll waits its turn on the shared limiter
+func pollSection(index int, watch StackWatch, limiter <-chan time.Time, options Options, updates chan<- stackSection) {
+	section := stackSection{
+		index:  index,
+		info:   watch.Info,
+		status: cloudformation.ResourceStatus(watch.Status),
+		rows:   make(map[string]data.DisplayRow),
+	}
+
+	eventIds := make(map[string]bool)
+	nested := newNestedStacks()
+
+	for {
+		sent := section
+		sent.rows = data.CopyDisplayRows(section.rows)
+		updates <- sent
+
+		if section.finished() {
+			return
+		}
+
+		<-limiter
+
+		events, err := cfn.GetStackEvents(section.info, watch.Since, options.MaxStackEvents)
+		if err != nil {
+			section.err = err
+			continue
+		}
+
+		fresh := make([]cloudformation.StackEvent, 0)
+
+		for _, event := range utils.ReverseEvents(events) {
+			if eventIds[*event.EventId] {
+				continue
+			}
+
+			eventIds[*event.EventId] = true
+			fresh = append(fresh, event)
+			nested.track(event, *event.LogicalResourceId)
+		}
+
+		nested.poll(watch.Since, options.MaxStackEvents, eventIds, section.rows)
+
+		for _, event := range fresh {
+			if data.IsStackStatusEvent(event) {
+				section.status = event.ResourceStatus
+				continue
+			}
+
+			section.rows[*event.LogicalResourceId] = data.MergeEventRow(section.rows[*event.LogicalResourceId], event)
+		}
+	}
+}
+
+// showSections draws a box per stack and redraws a box whenever its stack changes, until every stack finishes or the user quits
+func showSections(watches []StackWatch, options Options, updates <-chan stackSection) []stackSection {
+	app := tview.NewApplication()
+	view := tview.NewFlex().SetDirection(tview.FlexRow)
+
+	sections := make([]stackSection, len(watches))
+	boxes := make([]*tview.TextView, len(watches))
+
+	for i, watch := range watches {
+		sections[i] = stackSection{index: i, info: watch.Info, status: cloudformation.ResourceStatus(watch.Status)}
+
+		boxes[i] = tview.NewTextView().SetScrollable(true).SetDynamicColors(true).SetWrap(false)
+		boxes[i].SetBorder(true).SetTitle(sectionTitle(sections[i]))
+
+		view.AddItem(boxes[i], 0, 1, false)
+	}
+
+	// the user may quit while updates still arrive
+	var lock sync.Mutex
+
+	go func() {
+		for remaining := len(watches); remaining > 0; {
+			section := <-updates
+
+			lock.Lock()
+			sections[section.index] = section
+			lock.Unlock()
+
+			if section.finished() {
+				remaining--
+			}
+
+			app.QueueUpdateDraw(func() {
+				boxes[section.index].SetTitle(sectionTitle(section))
+				boxes[section.index].SetText(ParseDisplayRows(section.rows, options))
+			})
+		}
+
+		app.Stop()
+	}()
+
+	stopRedrawOnResize := redrawOnResize(app)
+	defer stopRedrawOnResize()
+
+	if err := app.SetRoot(view, true).Run(); err != nil {
+		panic(err)
+	}
+
+	lock.Lock()
+	defer lock.Unlock()
+
+	return append([]stackSection{}, sections...)
+}
+
+// printSections prints each row change and status change as a line prefixed with its stack, until every stack finishes
+func printSections(watches []StackWatch, options Options, updates <-chan stackSection) []stackSection {
+	sections := make([]stackSection, len(watches))
+	printed := make([]map[string]data.DisplayRow, len(watches))
+
+	for i, watch := range watches {
+		sections[i] = stackSection{index: i, info: watch.Info}
+		printed[i] = make(map[string]data.DisplayRow)
+	}
+
+	for remaining := len(watches); remaining > 0; {
+		section := <-updates
+		prefix := colors.Purple(section.info.StackName) + " "
+
+		if section.status != sections[section.index].status {
+			fmt.Println(prefix + stackStatusColor(section.status)(section.status))
+		}
+
+		for _, key := range data.DiffRowMaps(printed[section.index], section.rows) {
+			if row, ok := section.rows[key]; ok {
+				fmt.Println(prefix + formatLine(row, options))
+			}
+		}
+
+		sections[section.index] = section
+		printed[section.index] = section.rows
+
+		if section.finished() {
+			remaining--
+		}
+	}
+
+	return sections
+}
+
+func sectionTitle(section stackSection) string {
+	status := "[green::b]" + string(section.status) + "[-]"
+
+	switch {
+	case section.err != nil:
+		status = "[red::b]ERROR[-]"
+	case utils.ContainsStackStatus(data.NegativeStackStatus, section.status):
+		status = "[red::b]" + string(section.status) + "[-]"
+	case utils.ContainsStackStatus(data.PendingStackStatus, section.status):
+		status = "[yellow::b]" + string(section.status) + "[-]"
+	}
+
+	return " " + section.info.StackName + " " + status + " "
+}
+
+// stackStatusColor picks the ANSI tint of a stack status, which unlike resource statuses treats rollbacks as failures
+func stackStatusColor(status cloudformation.ResourceStatus) func(...interface{}) string {
+	if utils.ContainsStackStatus(data.NegativeStackStatus, status) {
+		return colors.Red
+	}
+
+	if utils.ContainsStackStatus(data.PendingStackStatus, status) {
+		return colors.Yellow
+	}
+
+	return colors.Green
+}
+
+func sectionSummary(section stackSection) string {
+	if section.err != nil {
+		return colors.Error(fmt.Sprintf("%s could not be watched: %s", section.info.StackName, section.err.Error()))
+	}
+
+	message := fmt.Sprintf("%s is %s", section.info.StackName, section.status)
+
+	if utils.ContainsStackStatus(data.PendingStackStatus, section.status) {
+		return colors.Pending(message)
+	}
+
+	return colors.Status(message)
+}
@@ -0,0 +1,98 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/utils"
+)
+
+// nestedStack is a stack created by a nested stack resource, whose events are shown below that resource's row
+type nestedStack struct {
+	info data.StackInfo
+
+	// key is the row key of the nested stack resource
+	key string
+
+	// finished is set once the stack reaches a terminal status, until its resource is changed again
+	finished bool
+}
+
+// nestedStacks follows every stack nested, at any depth, in a watched stack
+type nestedStacks struct {
+	stacks map[string]*nestedStack
+	order  []string
+}
+
+func newNestedStacks() *nestedStacks {
+	return &nestedStacks{stacks: make(map[string]*nestedStack)}
+}
+
+// track follows the stack created by a nested stack resource event, whose row has the given key. A rollback or update of the
+// resource after its stack finished is followed again
+func (nested *nestedStacks) track(event cloudformation.StackEvent, key string) {
+	stackID, ok := data.NestedStackID(event)
+	if !ok {
+		return
+	}
+
+	stack, ok := nested.stacks[stackID]
+	if !ok {
+		stack = &nestedStack{info: data.StackInfo{StackName: *event.LogicalResourceId, StackID: stackID}, key: key}
+		nested.stacks[stackID] = stack
+		nested.order = append(nested.order, stackID)
+	}
+
+	if utils.ContainsResourceStatus(data.PendingEventStatus, event.ResourceStatus) {
+		stack.finished = false
+	}
+}
+
+// poll merges the new events of every nested stack still in progress into the rows, keyed below their nested stack resource.
+// Stacks found nested in them are followed in the same poll. Nested stacks only add detail, so one that can't be described is
+// skipped and the watched stack's own status still decides the outcome. The failed events are returned in order
+func (nested *nestedStacks) poll(since time.Time, limit int, eventIds map[string]bool, rows map[string]data.DisplayRow) []cloudformation.StackEvent {
+	failures := make([]cloudformation.StackEvent, 0)
+
+	// stacks found during the poll are appended to the order, and polled before it ends
+	for i := 0; i < len(nested.order); i++ {
+		stack := nested.stacks[nested.order[i]]
+		if stack.finished {
+			continue
+		}
+
+		events, err := cfn.GetStackEvents(stack.info, since, limit)
+		if err != nil {
+			continue
+		}
+
+		for _, event := range utils.ReverseEvents(events) {
+			if eventIds[*event.EventId] {
+				continue
+			}
+
+			eventIds[*event.EventId] = true
+
+			if data.IsStackStatusEvent(event) {
+				stack.finished = !utils.ContainsStackStatus(data.PendingStackStatus, event.ResourceStatus)
+				continue
+			}
+
+			key := data.NestedRowKey(stack.key, *event.LogicalResourceId)
+
+			row := data.MergeEventRow(rows[key], event)
+			row.Parent = stack.key
+			rows[key] = row
+
+			nested.track(event, key)
+
+			if utils.ContainsResourceStatus(data.NegativeEventStatus, event.ResourceStatus) {
+				failures = append(failures, event)
+			}
+		}
+	}
+
+	return failures
+}
@@ -0,0 +1,81 @@
+package ui
+
+import (
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/data"
+)
+
+// Options holds the user-configurable settings for displaying a stack operation
+type Options struct {
+	//AlwaysRefresh redraws the display on every poll, even when no rows changed
+	AlwaysRefresh bool
+
+	// WaitStates are stack statuses that end the watch with success as soon as the stack reaches one, on top of the terminal statuses
+	WaitStates []cloudformation.StackStatus
+
+	// ExitOnCleanup treats UPDATE_COMPLETE_CLEANUP_IN_PROGRESS as success and stops watching before old resources finish deleting
+	ExitOnCleanup bool
+
+	// AutoApprove executes the operation without asking, when the output allows it
+	AutoApprove bool
+
+	// ShortTypes abbreviates resource types in the display, as data.ShortResourceType does. Exports keep the full types
+	ShortTypes bool
+
+	// VerboseChanges lists the properties and change sources behind each modified resource in the preview
+	VerboseChanges bool
+
+	// PropertyValues are the values of the changed properties of each resource, shown in the preview when set
+	PropertyValues map[string][]data.PropertyValue
+
+	// Execute holds the settings used when the operation executes a change set
+	Execute cfn.ExecuteChangeSetOptions
+
+	// Delete holds the settings used when the operation deletes the stack
+	Delete cfn.DeleteStackOptions
+
+	// Output is how the operation is rendered
+	Output OutputFormat
+
+	// LogFile is appended every version of every row observed during the watch as newline-delimited JSON. Empty writes no log
+	LogFile string
+
+	// MaxStackEvents caps how many of the most recent stack events are fetched on each poll. Zero or less means no cap
+	MaxStackEvents int
+}
+
+// displayType is the resource type as displayed, abbreviated when ShortTypes is set
+func (options Options) displayType(resourceType string) string {
+	if options.ShortTypes {
+		return data.ShortResourceType(resourceType)
+	}
+
+	return resourceType
+}
+
+// OutputFormat determines how results are written to stdout
+type OutputFormat string
+
+const (
+	// OutputText renders results as human readable text
+	OutputText OutputFormat = "text"
+
+	// OutputJSON renders results as JSON
+	OutputJSON OutputFormat = "json"
+
+	// OutputTable renders an operation as an interactive, redrawn table
+	OutputTable OutputFormat = "table"
+
+	// OutputLines renders an operation as append-only lines, one per change or event
+	OutputLines OutputFormat = "lines"
+
+	// OutputCompact renders an operation like OutputLines, but prints one line per resource once it finishes instead of every event
+	OutputCompact OutputFormat = "compact"
+
+	// OutputSummaryTable renders an operation like OutputLines, then reconciles the planned changes with the actual ones in a table
+	OutputSummaryTable OutputFormat = "summary-table"
+
+	// OutputGitHub renders an operation like OutputLines, then annotates failed resources and writes a job summary for GitHub Actions
+	OutputGitHub OutputFormat = "github"
+)
@@ -0,0 +1,41 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+)
+
+// printReconciliation prints the planned and actual count of each outcome, then every resource whose outcome wasn't planned
+func printReconciliation(reconciliation data.Reconciliation) {
+	fmt.Println()
+	fmt.Printf("%-12s %8s %8s\n", "Outcome", "Planned", "Actual")
+
+	for _, outcome := range data.Outcomes {
+		planned, actual := reconciliation.Planned[outcome], reconciliation.Actual[outcome]
+		if planned == 0 && actual == 0 {
+			continue
+		}
+
+		line := fmt.Sprintf("%-12s %8d %8d", outcome, planned, actual)
+		if planned != actual {
+			line = colors.Warning(line)
+		}
+
+		fmt.Println(line)
+	}
+
+	fmt.Println()
+
+	if len(reconciliation.Discrepancies) == 0 {
+		fmt.Println(colors.Success("Every resource changed as planned"))
+		return
+	}
+
+	fmt.Println(colors.Error(fmt.Sprintf("%d resource(s) didn't change as planned:", len(reconciliation.Discrepancies))))
+
+	for _, discrepancy := range reconciliation.Discrepancies {
+		fmt.Println("  " + discrepancy)
+	}
+}
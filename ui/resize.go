@@ -0,0 +1,25 @@
+package ui
+
+import (
+	"github.com/gdamore/tcell"
+	"github.com/rivo/tview"
+)
+
+// syncAfterResize makes the next draw repaint every cell of the terminal. tview redraws into its buffer on resize, but the
+// terminal may have reflowed what was on screen, so only a full repaint clears the leftovers
+func syncAfterResize(app *tview.Application) func() {
+	resized := false
+
+	app.SetAfterDrawFunc(func(screen tcell.Screen) {
+		if resized {
+			resized = false
+			screen.Sync()
+		}
+	})
+
+	return func() {
+		app.QueueUpdateDraw(func() {
+			resized = true
+		})
+	}
+}
@@ -0,0 +1,38 @@
+//go:build !windows
+// +build !windows
+
+package ui
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/rivo/tview"
+)
+
+// redrawOnResize fully redraws the app whenever the terminal is resized, until the returned function is called
+func redrawOnResize(app *tview.Application) func() {
+	onResize := syncAfterResize(app)
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+
+	signal.Notify(signals, syscall.SIGWINCH)
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+				onResize()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
@@ -0,0 +1,8 @@
+package ui
+
+import "github.com/rivo/tview"
+
+// redrawOnResize is a no-op on Windows, which has no SIGWINCH. tview still redraws on the resize events it receives
+func redrawOnResize(app *tview.Application) func() {
+	return func() {}
+}
@@ -0,0 +1,314 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/blueseph/cirrus/cfn"
+	"github.com/blueseph/cirrus/colors"
+	"github.com/blueseph/cirrus/data"
+	"github.com/blueseph/cirrus/utils"
+)
+
+// operationOutcome is how a displayed operation finished
+type operationOutcome struct {
+	message string
+	err     error
+
+	// rootCause is the failure that most likely caused the operation to fail, kept so rollback events can't bury it
+	rootCause *cloudformation.StackEvent
+
+	// failures are the failed resource events of the operation
+	failures []cloudformation.StackEvent
+
+	status   cloudformation.ResourceStatus
+	duration time.Duration
+	rows     map[string]data.DisplayRow
+}
+
+const (
+	rollbackNotice     string = "Operation failed. View failure log after rollback completes"
+	eventsDeniedNotice string = "Not allowed to describe stack events. Following the stack status only"
+)
+
+// startOperation executes the operation and returns the cutoff for events belonging to it
+func startOperation(operation cfn.StackOperation, info data.StackInfo, options Options) (time.Time, error) {
+	// events at or before the latest existing event belong to previous operations
+	since, err := cfn.GetLatestStackEventTime(info)
+	if err != nil && !cfn.IsAccessDenied(err) {
+		return since, err
+	}
+
+	if operation == cfn.StackOperationDelete {
+		err = cfn.DeleteStack(info, options.Delete)
+	} else {
+		err = cfn.ExecuteChangeSet(info, options.Execute)
+	}
+
+	return since, err
+}
+
+// watchEvents polls the events of an operation, rendering the rows whenever they change, until the stack reaches a terminal status
+func watchEvents(info data.StackInfo, since time.Time, activatedDisplayRows map[string]data.DisplayRow, options Options, render func(map[string]data.DisplayRow), notify func(string)) operationOutcome {
+	started := time.Now()
+
+	log, err := openRowLog(options.LogFile, info)
+	if err != nil {
+		return aborted(err)
+	}
+	defer log.close()
+
+	// the change set rows are logged before any event updates them
+	if err := log.write(activatedDisplayRows); err != nil {
+		return aborted(err)
+	}
+
+	// every render follows a change to the rows, so logging alongside it catches each version of a row whatever the output
+	logged := func(rows map[string]data.DisplayRow) {
+		if err := log.write(rows); err != nil {
+			notify("Unable to write to the log file: " + err.Error())
+		}
+
+		render(rows)
+	}
+
+	result := pollEvents(info, since, activatedDisplayRows, options, logged, notify)
+	result.duration = time.Since(started)
+	result.rows = activatedDisplayRows
+
+	return result
+}
+
+func pollEvents(info data.StackInfo, since time.Time, activatedDisplayRows map[string]data.DisplayRow, options Options, render func(map[string]data.DisplayRow), notify func(string)) operationOutcome {
+	rendered := data.CopyDisplayRows(activatedDisplayRows)
+
+	eventIds := make(map[string]bool)
+	failures := make([]cloudformation.StackEvent, 0)
+	nested := newNestedStacks()
+
+	for {
+		events, err := cfn.GetStackEvents(info, since, options.MaxStackEvents)
+		if cfn.IsAccessDenied(err) {
+			notify(eventsDeniedNotice)
+			return pollStackStatus(info, options, notify)
+		}
+
+		if cfn.IsTimeout(err) {
+			render(activatedDisplayRows)
+			return timedOut(activatedDisplayRows)
+		}
+
+		if err != nil {
+			return aborted(err)
+		}
+
+		fresh := make([]cloudformation.StackEvent, 0)
+
+		for _, event := range utils.ReverseEvents(events) {
+			if eventIds[*event.EventId] {
+				continue
+			}
+
+			eventIds[*event.EventId] = true
+			fresh = append(fresh, event)
+			nested.track(event, *event.LogicalResourceId)
+		}
+
+		// nested stacks finish before their resources, so their rows and failures are merged before the stack can finish
+		failures = append(failures, nested.poll(since, options.MaxStackEvents, eventIds, activatedDisplayRows)...)
+
+		for _, event := range fresh {
+			if data.IsStackStatusEvent(event) {
+				if utils.ContainsStackStatus(data.RollbackStackStatus, event.ResourceStatus) {
+					notify(rollbackNotice)
+				}
+
+				if utils.ContainsStackStatus(options.WaitStates, event.ResourceStatus) && len(failures) == 0 {
+					render(activatedDisplayRows)
+					return reachedWaitState(event.ResourceStatus)
+				}
+
+				if options.ExitOnCleanup && isCleanupStatus(event.ResourceStatus) && len(failures) == 0 {
+					return succeededBeforeCleanup(event.ResourceStatus)
+				}
+
+				if !utils.ContainsStackStatus(data.PendingStackStatus, event.ResourceStatus) {
+					render(activatedDisplayRows)
+
+					if len(failures) > 0 || utils.ContainsStackStatus(data.NegativeStackStatus, event.ResourceStatus) {
+						return failed(event.ResourceStatus, failures)
+					}
+
+					return succeeded(event.ResourceStatus)
+				}
+			} else {
+				activatedDisplayRows[*event.LogicalResourceId] = data.MergeEventRow(activatedDisplayRows[*event.LogicalResourceId], event)
+
+				if utils.ContainsResourceStatus(data.NegativeEventStatus, event.ResourceStatus) {
+					failures = append(failures, event)
+				}
+			}
+		}
+
+		if options.AlwaysRefresh || len(data.DiffRowMaps(rendered, activatedDisplayRows)) > 0 {
+			render(activatedDisplayRows)
+			rendered = data.CopyDisplayRows(activatedDisplayRows)
+		}
+
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+// pollStackStatus follows only the overall stack status, for callers that aren't allowed to describe stack events
+func pollStackStatus(info data.StackInfo, options Options, notify func(string)) operationOutcome {
+	rolledBack := false
+
+	for {
+		stack, err := cfn.RefreshStack(info.StackID)
+		if cfn.IsTimeout(err) {
+			return timedOut(nil)
+		}
+
+		if err != nil {
+			return aborted(err)
+		}
+
+		status := cloudformation.ResourceStatus(stack.Stacks[0].StackStatus)
+
+		if !rolledBack && utils.ContainsStackStatus(data.RollbackStackStatus, status) {
+			rolledBack = true
+			notify(rollbackNotice)
+		}
+
+		if utils.ContainsStackStatus(options.WaitStates, status) {
+			return reachedWaitState(status)
+		}
+
+		if options.ExitOnCleanup && isCleanupStatus(status) {
+			return succeededBeforeCleanup(status)
+		}
+
+		if !utils.ContainsStackStatus(data.PendingStackStatus, status) {
+			if utils.ContainsStackStatus(data.NegativeStackStatus, status) {
+				result := failed(status, nil)
+
+				if reason := stack.Stacks[0].StackStatusReason; reason != nil {
+					result.message = colors.Error("Operation failed: " + data.Redact(*reason))
+				}
+
+				return result
+			}
+
+			return succeeded(status)
+		}
+
+		time.Sleep(500 * time.Millisecond)
+	}
+}
+
+func succeeded(status cloudformation.ResourceStatus) operationOutcome {
+	return operationOutcome{message: colors.Success("Operation Succeeded"), status: status}
+}
+
+func succeededBeforeCleanup(status cloudformation.ResourceStatus) operationOutcome {
+	message := colors.Success("Operation Succeeded (cleanup in progress)") + "\n"
+	message += colors.Status("Resources from the prior version may still be deleting")
+
+	return operationOutcome{message: message, status: status}
+}
+
+func reachedWaitState(status cloudformation.ResourceStatus) operationOutcome {
+	return operationOutcome{message: colors.Success(fmt.Sprintf("Stack reached %s, stopped watching", status)), status: status}
+}
+
+func failed(status cloudformation.ResourceStatus, failures []cloudformation.StackEvent) operationOutcome {
+	errorMsg := colors.Error("Operation failed. The following errors prevented the stack operation from succeeding: \n\n")
+
+	for i, failure := range failures {
+		errorMsg += colors.Magenta(*failure.LogicalResourceId) + " - " + statusReason(failure) + reasonDocs(failure)
+		if i < len(failures)-1 {
+			errorMsg += "\n"
+		}
+	}
+
+	result := operationOutcome{
+		err:      errors.New(colors.Error(fmt.Sprintf("Stack finished in %s", status))),
+		status:   status,
+		failures: failures,
+	}
+
+	if rootCause, ok := data.RootCause(failures); ok {
+		result.rootCause = &rootCause
+		errorMsg += "\n\n" + colors.Error("Root cause: ") + colors.Magenta(*rootCause.LogicalResourceId) + " - " + statusReason(rootCause) + reasonDocs(rootCause)
+	}
+
+	result.message = errorMsg
+
+	return result
+}
+
+func aborted(err error) operationOutcome {
+	return operationOutcome{
+		message: colors.Error("Operation aborted"),
+		err:     err,
+	}
+}
+
+// timedOut reports the resources still in progress when the timeout elapsed
+func timedOut(rows map[string]data.DisplayRow) operationOutcome {
+	err := &cfn.TimeoutError{Pending: data.PendingResources(rows)}
+
+	return operationOutcome{
+		message: colors.Error("Operation timed out"),
+		err:     err,
+	}
+}
+
+func declined(operation cfn.StackOperation) operationOutcome {
+	declined := "change set"
+
+	if operation == cfn.StackOperationDelete {
+		declined = "delete"
+	}
+
+	return operationOutcome{message: colors.Status(fmt.Sprintf("User declined %s", declined))}
+}
+
+func toDeployResult(info data.StackInfo, result operationOutcome) data.DeployResult {
+	return data.DeployResult{
+		StackName: info.StackName,
+		StackID:   info.StackID,
+		Executed:  result.status != "",
+		Status:    cloudformation.StackStatus(result.status),
+		Duration:  result.duration,
+		Rows:      result.rows,
+		RootCause: result.rootCause,
+	}
+}
+
+func statusReason(event cloudformation.StackEvent) string {
+	if event.ResourceStatusReason == nil {
+		return "No reason provided"
+	}
+
+	return data.Redact(*event.ResourceStatusReason)
+}
+
+// reasonDocs is a line linking the documentation for a known kind of failure, or empty
+func reasonDocs(event cloudformation.StackEvent) string {
+	if event.ResourceStatusReason == nil {
+		return ""
+	}
+
+	if url, ok := data.DocsForReason(*event.ResourceStatusReason); ok {
+		return "\n  " + colors.Docs(url)
+	}
+
+	return ""
+}
+
+func isCleanupStatus(status cloudformation.ResourceStatus) bool {
+	return string(status) == string(cloudformation.StackStatusUpdateCompleteCleanupInProgress)
+}
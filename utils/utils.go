@@ -1,6 +1,13 @@
 package utils
 
 import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"unicode"
+
 	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
 )
 
@@ -39,3 +46,76 @@ func ReverseEvents(s []cloudformation.StackEvent) []cloudformation.StackEvent {
 
 	return a
 }
+
+// AskYesNoQuestion prints a question and reads stdin until the user answers Y or N
+func AskYesNoQuestion(question string) (bool, error) {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Println(question)
+
+	for {
+		char, _, err := reader.ReadRune()
+
+		if err != nil {
+			return false, err
+		}
+
+		char = unicode.ToLower(char)
+
+		switch char {
+		case 'y':
+			return true, nil
+		case 'n':
+			return false, nil
+		default:
+			fmt.Println("Please enter Y/N")
+		}
+	}
+}
+
+// AskForText prints a question and reads one line from stdin as the answer, without surrounding whitespace
+func AskForText(question string) (string, error) {
+	reader := bufio.NewReader(os.Stdin)
+
+	fmt.Println(question)
+
+	answer, err := reader.ReadString('\n')
+	if err != nil && answer == "" {
+		return "", err
+	}
+
+	return strings.TrimSpace(answer), nil
+}
+
+// IsTerminal determines if a file, usually stdout, is an interactive terminal rather than a pipe or file
+func IsTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// SplitList splits a comma-separated list, trimming whitespace and dropping empty items
+func SplitList(list string) []string {
+	items := make([]string, 0)
+
+	for _, item := range strings.Split(list, ",") {
+		if trimmed := strings.TrimSpace(item); trimmed != "" {
+			items = append(items, trimmed)
+		}
+	}
+
+	return items
+}
+
+// GitCommitSubject returns the subject of the commit checked out in the working directory, or an empty string outside a git repository
+func GitCommitSubject() string {
+	subject, err := exec.Command("git", "log", "-1", "--format=%s").Output()
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(subject))
+}